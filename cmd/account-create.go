@@ -0,0 +1,558 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/kahunacohen/trackit/internal/config"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+const unmappedColumnText = "~ (not imported)"
+
+// The trackit tables a CSV column can be mapped to.
+var headerTables = []string{"transaction_date", "counter_party", "amount", "deposit", "withdrawl"}
+
+// Date layouts tried, in order, when guessing the date column of a sample CSV. When a
+// column is ambiguous (e.g. 03/04/2025) the month-first layout wins.
+var sampleDateLayouts = []string{
+	"2006-01-02",
+	"01/02/2006",
+	"02/01/2006",
+	"1/2/2006",
+	"2/1/2006",
+	"01/02/06",
+	"02/01/06",
+	"02.01.2006",
+	"2.1.2006",
+	"01-02-2006",
+	"02-01-2006",
+	"2006/01/02",
+	"Jan 2, 2006",
+	"02 Jan 2006",
+	"2 Jan 2006",
+}
+
+// numberFormat is how a bank writes amounts: its thousands separator, which may be empty,
+// and its decimal point.
+type numberFormat struct {
+	thousands string
+	decimal   string
+}
+
+// Number formats tried, in order, when guessing the amount columns of a sample CSV, e.g.
+// 1234.56, 1,234.56, 1.234,56 and 1 234,56. A later format is only picked when it makes
+// more columns numeric, so 1.234 is read as a decimal number, unless other amounts in the
+// file are written like 1.234,56.
+var sampleNumberFormats = []numberFormat{
+	{thousands: "", decimal: "."},
+	{thousands: ",", decimal: "."},
+	{thousands: ".", decimal: ","},
+	{thousands: " ", decimal: ","},
+	{thousands: " ", decimal: "."},
+	{thousands: "", decimal: ","},
+}
+
+var (
+	payeeHeaderRe     = regexp.MustCompile(`(?i)payee|merchant|description|details|name|counter|narrative|memo`)
+	amountHeaderRe    = regexp.MustCompile(`(?i)amount|sum|total`)
+	depositHeaderRe   = regexp.MustCompile(`(?i)deposit|credit|income|paid in|money in`)
+	withdrawlHeaderRe = regexp.MustCompile(`(?i)withdraw|debit|charge|paid out|money out`)
+	ignoreHeaderRe    = regexp.MustCompile(`(?i)balance|reference|^ref|^id$|number|^no\.?$|check|cheque`)
+)
+
+var accountCreateCmd = &cobra.Command{
+	Use:     "create",
+	Aliases: []string{"add"},
+	Args:    cobra.ExactArgs(1),
+	Short:   "Creates an account in trackit.yaml from a sample CSV file. account add <key> --from-csv <file>",
+	Long: `Creates a bank account from a sample CSV file downloaded from your bank. trackit reads the header
+row, guesses which columns hold the transaction date, payee and amount (or deposit/withdrawl),
+detects the date layout, thousands separator and decimal point and asks you to confirm or correct the guess.
+The account block is then written to trackit.yaml and the account is created in the database.
+
+The account key should be a name with underscores that will be part of the file names of this
+account's CSV files. E.g.:
+
+$ trackit account add bank_of_america --from-csv ~/Downloads/stmt.csv`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		verbose, _ = rootCmd.PersistentFlags().GetBool("verbose")
+		accountName := args[0]
+		if strings.ContainsAny(accountName, " \t") {
+			return fmt.Errorf("account key '%s' must not contain spaces. Separate words with '_'", accountName)
+		}
+		csvPath, _ := cmd.Flags().GetString("from-csv")
+		currency, _ := cmd.Flags().GetString("currency")
+		_, configPath, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		conf, err := config.ParseConfig(configPath)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			logF(verbose, "no config file at %s, one will be created", configPath)
+			conf = &config.Config{}
+		}
+		if _, ok := conf.Accounts[accountName]; ok {
+			return fmt.Errorf("account '%s' already exists in trackit.yaml", accountName)
+		}
+		records, err := readSampleCSV(csvPath)
+		if err != nil {
+			return err
+		}
+		guess := guessAccountColumns(records)
+		for {
+			renderAccountGuess(guess)
+			prompt := promptui.Select{
+				Label: "Save this account to trackit.yaml?",
+				Items: []string{"Yes", "Edit mapping", "Cancel"},
+			}
+			_, choice, err := prompt.Run()
+			if err != nil {
+				return fmt.Errorf("prompt failed %w", err)
+			}
+			if choice == "Cancel" {
+				return nil
+			}
+			if choice == "Edit mapping" {
+				if err := editAccountGuess(&guess); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := guess.validate(); err != nil {
+				fmt.Println(err)
+				continue
+			}
+			break
+		}
+		if currency == "" {
+			currency = conf.BaseCurrency
+		}
+		currencyPrompt := promptui.Prompt{
+			Label:     "Currency of this account",
+			Default:   currency,
+			AllowEdit: true,
+			Validate: func(s string) error {
+				if len(s) != 3 {
+					return errors.New("currency symbol must be three characters")
+				}
+				return nil
+			},
+		}
+		currency, err = currencyPrompt.Run()
+		if err != nil {
+			return fmt.Errorf("prompt failed %w", err)
+		}
+		account := guess.account(strings.ToUpper(currency))
+		if err := config.AddAccount(configPath, accountName, account); err != nil {
+			return fmt.Errorf("error writing account to config: %w", err)
+		}
+		logF(verbose, "wrote account %s to %s", accountName, configPath)
+
+		conf, err = config.ParseConfig(configPath)
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		if err := initAccounts(conf, db); err != nil {
+			return fmt.Errorf("error initializing accounts: %w", err)
+		}
+		fmt.Printf("Added account %s. Put its CSV files, with %s in the file name, in your data directory and run trackit transaction import\n", accountName, accountName)
+		return nil
+	},
+}
+
+func init() {
+	accountCreateCmd.Flags().StringP("from-csv", "f", "", "path to a sample CSV file downloaded from the bank")
+	accountCreateCmd.MarkFlagRequired("from-csv")
+	accountCreateCmd.Flags().StringP("currency", "c", "", "currency symbol of the account. Defaults to base_currency")
+	accountCmd.AddCommand(accountCreateCmd)
+}
+
+type columnGuess struct {
+	Header string
+	Sample string
+	// Table is one of headerTables, or empty when the column isn't imported.
+	Table string
+}
+
+type accountGuess struct {
+	Columns            []columnGuess
+	DateLayout         string
+	ThousandsSeparator string
+	DecimalSeparator   string
+	// values holds the data of each column, indexed like Columns.
+	values [][]string
+}
+
+func (g accountGuess) account(currency string) config.Account {
+	headers := make([]config.Header, len(g.Columns))
+	for i, col := range g.Columns {
+		headers[i] = config.Header{Name: col.Header, Table: col.Table}
+	}
+	return config.Account{
+		Currency:           currency,
+		DateLayout:         g.DateLayout,
+		Headers:            headers,
+		ThousandsSeparator: g.ThousandsSeparator,
+		DecimalSeparator:   g.DecimalSeparator,
+	}
+}
+
+func (g accountGuess) validate() error {
+	counts := make(map[string]int)
+	for _, col := range g.Columns {
+		if col.Table != "" {
+			counts[col.Table]++
+		}
+	}
+	for table, n := range counts {
+		if n > 1 {
+			return fmt.Errorf("more than one column is mapped to %s", table)
+		}
+	}
+	if counts["transaction_date"] == 0 || counts["counter_party"] == 0 {
+		return errors.New("a column must be mapped to transaction_date and to counter_party")
+	}
+	if counts["amount"] == 0 && (counts["deposit"] == 0 || counts["withdrawl"] == 0) {
+		return errors.New("a column must be mapped to amount, or two columns to deposit and withdrawl")
+	}
+	if g.DateLayout == "" {
+		return errors.New("no date layout set")
+	}
+	for i, col := range g.Columns {
+		if col.Table != "transaction_date" {
+			continue
+		}
+		for _, s := range nonEmpty(g.values[i]) {
+			if _, err := time.Parse(g.DateLayout, s); err != nil {
+				return fmt.Errorf("date '%s' does not match date layout %s", s, g.DateLayout)
+			}
+		}
+	}
+	return nil
+}
+
+func readSampleCSV(path string) ([][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %w", path, err)
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("there are less than 2 rows for file: %s", path)
+	}
+	records[0][0] = strings.TrimPrefix(records[0][0], "\ufeff")
+	return records, nil
+}
+
+// guessAccountColumns guesses the mapping of CSV columns to trackit tables by looking at the
+// header names and at the data in each column.
+func guessAccountColumns(records [][]string) accountGuess {
+	headers := records[0]
+	rows := records[1:]
+	values := make([][]string, len(headers))
+	for _, row := range rows {
+		for i := range headers {
+			if i < len(row) {
+				values[i] = append(values[i], strings.TrimSpace(row[i]))
+			} else {
+				values[i] = append(values[i], "")
+			}
+		}
+	}
+	guess := accountGuess{Columns: make([]columnGuess, len(headers)), values: values}
+	for i, header := range headers {
+		guess.Columns[i] = columnGuess{Header: header, Sample: firstNonEmpty(values[i])}
+	}
+
+	// The first column whose values all parse with one of the known layouts is the date.
+	dateCol := -1
+	for i := range headers {
+		if layout := guessDateLayout(values[i]); layout != "" {
+			dateCol = i
+			guess.DateLayout = layout
+			guess.Columns[i].Table = "transaction_date"
+			break
+		}
+	}
+
+	var numericCols []int
+	format := sampleNumberFormats[0]
+	for _, f := range sampleNumberFormats {
+		var cols []int
+		for i := range headers {
+			if i != dateCol && !ignoreHeaderRe.MatchString(headers[i]) && isNumericColumn(values[i], f) {
+				cols = append(cols, i)
+			}
+		}
+		if len(cols) > len(numericCols) {
+			numericCols = cols
+			format = f
+		}
+	}
+	guess.ThousandsSeparator, guess.DecimalSeparator = format.thousands, format.decimal
+
+	amountCol := -1
+	for _, i := range numericCols {
+		if amountHeaderRe.MatchString(headers[i]) {
+			amountCol = i
+			break
+		}
+	}
+	if amountCol == -1 {
+		if deposit, withdrawl, ok := guessDepositWithdrawl(headers, values, numericCols, format); ok {
+			guess.Columns[deposit].Table = "deposit"
+			guess.Columns[withdrawl].Table = "withdrawl"
+		} else if len(numericCols) > 0 {
+			amountCol = numericCols[0]
+		}
+	}
+	if amountCol != -1 {
+		guess.Columns[amountCol].Table = "amount"
+	}
+
+	// The payee is the text column with the most distinct values, with a boost for
+	// columns with a payee-like header.
+	payeeCol, bestScore := -1, 0
+	for i := range headers {
+		if guess.Columns[i].Table != "" || slices.Contains(numericCols, i) {
+			continue
+		}
+		score := len(distinct(values[i]))
+		if payeeHeaderRe.MatchString(headers[i]) {
+			score += len(rows)
+		}
+		if score > bestScore {
+			payeeCol, bestScore = i, score
+		}
+	}
+	if payeeCol != -1 {
+		guess.Columns[payeeCol].Table = "counter_party"
+	}
+	return guess
+}
+
+// guessDepositWithdrawl looks for two numeric columns that are never both set in the
+// same row, which is how banks with separate deposit and withdrawl columns export them.
+func guessDepositWithdrawl(headers []string, values [][]string, numericCols []int, format numberFormat) (int, int, bool) {
+	for _, a := range numericCols {
+		for _, b := range numericCols {
+			if a >= b || !exclusiveColumns(values[a], values[b], format) {
+				continue
+			}
+			if depositHeaderRe.MatchString(headers[b]) || withdrawlHeaderRe.MatchString(headers[a]) {
+				return b, a, true
+			}
+			return a, b, true
+		}
+	}
+	return 0, 0, false
+}
+
+func exclusiveColumns(a []string, b []string, format numberFormat) bool {
+	var aSet, bSet bool
+	for i := range a {
+		aHas := hasAmount(a[i], format)
+		bHas := hasAmount(b[i], format)
+		if aHas && bHas {
+			return false
+		}
+		aSet = aSet || aHas
+		bSet = bSet || bHas
+	}
+	return aSet && bSet
+}
+
+// hasAmount reports whether a cell of a numeric column is set. Banks with separate deposit
+// and withdrawl columns leave the other one empty, or write 0 or 0.00 in it.
+func hasAmount(value string, format numberFormat) bool {
+	if value == "" {
+		return false
+	}
+	amount, err := parseAmount(value, format.thousands, format.decimal)
+	return err != nil || *amount != 0
+}
+
+func guessDateLayout(values []string) string {
+	samples := nonEmpty(values)
+	if len(samples) == 0 {
+		return ""
+	}
+	for _, layout := range sampleDateLayouts {
+		matches := true
+		for _, s := range samples {
+			if _, err := time.Parse(layout, s); err != nil {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return layout
+		}
+	}
+	return ""
+}
+
+func isNumericColumn(values []string, format numberFormat) bool {
+	samples := nonEmpty(values)
+	if len(samples) == 0 {
+		return false
+	}
+	for _, s := range samples {
+		if !format.matches(s) {
+			return false
+		}
+		if _, err := parseAmount(s, format.thousands, format.decimal); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether an amount is written in the format. Unlike parseAmount, the
+// thousands separator must separate groups of 3 digits before the decimal point, so
+// 1.234,56 doesn't match 1,234.56.
+func (f numberFormat) matches(amount string) bool {
+	digits := strings.Trim(strings.TrimSpace(amount), "+-"+currencySymbols)
+	whole, fraction, _ := strings.Cut(digits, f.decimal)
+	if strings.Contains(fraction, f.decimal) || f.thousands != "" && strings.Contains(fraction, f.thousands) {
+		return false
+	}
+	if f.thousands == "" {
+		return true
+	}
+	groups := strings.Split(whole, f.thousands)
+	for i, group := range groups {
+		if len(groups) > 1 && (len(group) > 3 || i > 0 && len(group) < 3) {
+			return false
+		}
+	}
+	return true
+}
+
+func renderAccountGuess(guess accountGuess) {
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Column", "Sample", "Maps to"})
+	for _, col := range guess.Columns {
+		mapsTo := col.Table
+		if mapsTo == "" {
+			mapsTo = "~"
+		}
+		t.AppendRow([]interface{}{col.Header, col.Sample, mapsTo})
+	}
+	t.AppendFooter(table.Row{"Date layout", guess.DateLayout, ""})
+	t.AppendFooter(table.Row{"Thousands separator", fmt.Sprintf("%q", guess.ThousandsSeparator), ""})
+	t.AppendFooter(table.Row{"Decimal separator", fmt.Sprintf("%q", guess.DecimalSeparator), ""})
+	t.Render()
+}
+
+func editAccountGuess(guess *accountGuess) error {
+	items := append([]string{unmappedColumnText}, headerTables...)
+	for i, col := range guess.Columns {
+		cursor := slices.Index(items, col.Table)
+		if cursor == -1 {
+			cursor = 0
+		}
+		prompt := promptui.Select{
+			Label:     fmt.Sprintf("Column '%s' (e.g. %s) maps to", col.Header, col.Sample),
+			Items:     items,
+			CursorPos: cursor,
+		}
+		_, result, err := prompt.Run()
+		if err != nil {
+			return fmt.Errorf("prompt failed %w", err)
+		}
+		if result == unmappedColumnText {
+			result = ""
+		}
+		guess.Columns[i].Table = result
+	}
+	layoutPrompt := promptui.Prompt{
+		Label:     "Date layout (Go reference date 2006-01-02)",
+		Default:   guess.DateLayout,
+		AllowEdit: true,
+	}
+	layout, err := layoutPrompt.Run()
+	if err != nil {
+		return fmt.Errorf("prompt failed %w", err)
+	}
+	guess.DateLayout = layout
+	separatorPrompt := promptui.Prompt{
+		Label:     "Thousands separator (empty for none)",
+		Default:   guess.ThousandsSeparator,
+		AllowEdit: true,
+	}
+	separator, err := separatorPrompt.Run()
+	if err != nil {
+		return fmt.Errorf("prompt failed %w", err)
+	}
+	guess.ThousandsSeparator = separator
+	decimalPrompt := promptui.Prompt{
+		Label:     "Decimal separator",
+		Default:   guess.DecimalSeparator,
+		AllowEdit: true,
+	}
+	decimal, err := decimalPrompt.Run()
+	if err != nil {
+		return fmt.Errorf("prompt failed %w", err)
+	}
+	guess.DecimalSeparator = decimal
+	return nil
+}
+
+func firstNonEmpty(values []string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func nonEmpty(values []string) []string {
+	var ret []string
+	for _, v := range values {
+		if v != "" {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+func distinct(values []string) map[string]bool {
+	ret := make(map[string]bool)
+	for _, v := range values {
+		if v != "" {
+			ret[v] = true
+		}
+	}
+	return ret
+}
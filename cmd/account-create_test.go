@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGuessAccountColumns(t *testing.T) {
+	tests := []struct {
+		name       string
+		records    [][]string
+		tables     []string
+		dateLayout string
+		thousands  string
+		decimal    string
+	}{
+		{
+			name: "amount column",
+			records: [][]string{
+				{"Date", "Description", "Amount", "Balance"},
+				{"2026-03-01", "SHUFERSAL DEAL", "-54.20", "945.80"},
+				{"2026-03-02", "SALARY", "1,000.00", "1,945.80"},
+			},
+			tables:     []string{"transaction_date", "counter_party", "amount", ""},
+			dateLayout: "2006-01-02",
+			thousands:  ",",
+			decimal:    ".",
+		},
+		{
+			name: "deposit and withdrawl columns with 0.00 cells",
+			records: [][]string{
+				{"Date", "Details", "Paid Out", "Paid In", "Balance"},
+				{"13/03/2026", "TESCO", "54.20", "0.00", "945.80"},
+				{"02/03/2026", "SALARY", "0", "1000.00", "1945.80"},
+				{"03/03/2026", "SAINSBURYS", "12.00", "", "1933.80"},
+			},
+			tables:     []string{"transaction_date", "counter_party", "withdrawl", "deposit", ""},
+			dateLayout: "02/01/2006",
+			thousands:  "",
+			decimal:    ".",
+		},
+		{
+			name: "decimal comma",
+			records: [][]string{
+				{"Buchungstag", "Empfänger", "Betrag"},
+				{"01.03.2026", "REWE", "-54,20"},
+				{"02.03.2026", "Gehalt", "1.000,00"},
+			},
+			tables:     []string{"transaction_date", "counter_party", "amount"},
+			dateLayout: "02.01.2006",
+			thousands:  ".",
+			decimal:    ",",
+		},
+		{
+			name: "space thousands separator",
+			records: [][]string{
+				{"Date", "Libellé", "Montant"},
+				{"2026-03-01", "CARREFOUR", "-54,20"},
+				{"2026-03-02", "SALAIRE", "1 000,00"},
+			},
+			tables:     []string{"transaction_date", "counter_party", "amount"},
+			dateLayout: "2006-01-02",
+			thousands:  " ",
+			decimal:    ",",
+		},
+		{
+			name: "currency symbols",
+			records: [][]string{
+				{"Date", "Payee", "Amount"},
+				{"2026-03-01", "AMAZON", "-$12.00"},
+				{"2026-03-02", "PAYROLL", "$1,234.00"},
+			},
+			tables:     []string{"transaction_date", "counter_party", "amount"},
+			dateLayout: "2006-01-02",
+			thousands:  ",",
+			decimal:    ".",
+		},
+	}
+	for _, test := range tests {
+		guess := guessAccountColumns(test.records)
+		var tables []string
+		for _, col := range guess.Columns {
+			tables = append(tables, col.Table)
+		}
+		if !reflect.DeepEqual(tables, test.tables) {
+			t.Errorf("%s: tables = %q, want %q", test.name, tables, test.tables)
+		}
+		if guess.DateLayout != test.dateLayout {
+			t.Errorf("%s: date layout = %q, want %q", test.name, guess.DateLayout, test.dateLayout)
+		}
+		if guess.ThousandsSeparator != test.thousands || guess.DecimalSeparator != test.decimal {
+			t.Errorf("%s: separators = %q %q, want %q %q", test.name, guess.ThousandsSeparator, guess.DecimalSeparator,
+				test.thousands, test.decimal)
+		}
+	}
+}
+
+func TestNumberFormatMatches(t *testing.T) {
+	tests := []struct {
+		amount  string
+		format  numberFormat
+		matches bool
+	}{
+		{"1234.56", numberFormat{"", "."}, true},
+		{"1,234.56", numberFormat{",", "."}, true},
+		{"-1,234,567.89", numberFormat{",", "."}, true},
+		{"1234.56", numberFormat{",", "."}, true},
+		{"12,34.56", numberFormat{",", "."}, false},
+		{"1,2345.56", numberFormat{",", "."}, false},
+		{"1.234,56", numberFormat{",", "."}, false},
+		{"1.234,56", numberFormat{".", ","}, true},
+		{"1 234,56", numberFormat{" ", ","}, true},
+		{"€1.234,56", numberFormat{".", ","}, true},
+		{"1,234.5.6", numberFormat{",", "."}, false},
+	}
+	for _, test := range tests {
+		if matches := test.format.matches(test.amount); matches != test.matches {
+			t.Errorf("%q.matches(%q) = %v, want %v", test.format, test.amount, matches, test.matches)
+		}
+	}
+}
+
+func TestExclusiveColumns(t *testing.T) {
+	format := numberFormat{",", "."}
+	tests := []struct {
+		a         []string
+		b         []string
+		exclusive bool
+	}{
+		{[]string{"54.20", ""}, []string{"", "1,000.00"}, true},
+		{[]string{"54.20", "0.00"}, []string{"0", "1,000.00"}, true},
+		{[]string{"54.20", "12.00"}, []string{"0.00", "1.00"}, false},
+		{[]string{"54.20", ""}, []string{"", ""}, false},
+		{[]string{"0.00", "0"}, []string{"1.00", "2.00"}, false},
+	}
+	for _, test := range tests {
+		if exclusive := exclusiveColumns(test.a, test.b, format); exclusive != test.exclusive {
+			t.Errorf("exclusiveColumns(%q, %q) = %v, want %v", test.a, test.b, exclusive, test.exclusive)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var accountCmd = &cobra.Command{
+	Use:     "account",
+	Aliases: []string{"acc"},
+	Short:   "Manages bank accounts",
+	Long:    `Manages the bank accounts defined in trackit.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(accountCmd)
+}
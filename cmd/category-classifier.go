@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/kahunacohen/trackit/internal/models"
+)
+
+// categorySuggestion is a category the classifier predicts for a transaction, with the
+// probability, between 0 and 1, it gives the prediction.
+type categorySuggestion struct {
+	Category   string
+	Confidence float64
+}
+
+// categoryClassifier is a naive Bayes classifier trained on the transactions that
+// already have a category. A transaction's features are the words of its counter party,
+// the order of magnitude and sign of its amount, and its account.
+type categoryClassifier struct {
+	// documents is the number of training transactions per category.
+	documents map[string]int
+	// features is the number of times each feature occurs per category.
+	features map[string]map[string]int
+	// featureTotals is the number of features per category.
+	featureTotals map[string]int
+	vocabulary    map[string]bool
+	total         int
+}
+
+func newCategoryClassifier(ctx context.Context, queries *models.Queries) (*categoryClassifier, error) {
+	transactions, err := queries.ReadCategorizedTransactions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading categorized transactions: %w", err)
+	}
+	classifier := &categoryClassifier{
+		documents:     make(map[string]int),
+		features:      make(map[string]map[string]int),
+		featureTotals: make(map[string]int),
+		vocabulary:    make(map[string]bool),
+	}
+	for _, transaction := range transactions {
+		classifier.train(transaction.CategoryName.String, transactionFeatures(transaction))
+	}
+	return classifier, nil
+}
+
+func (c *categoryClassifier) train(category string, features []string) {
+	c.documents[category]++
+	c.total++
+	if c.features[category] == nil {
+		c.features[category] = make(map[string]int)
+	}
+	for _, feature := range features {
+		c.features[category][feature]++
+		c.featureTotals[category]++
+		c.vocabulary[feature] = true
+	}
+}
+
+// suggest returns up to n categories for the transaction, most likely first. It returns
+// nothing when there are no categorized transactions to learn from.
+func (c *categoryClassifier) suggest(transaction models.TransactionsView, n int) []categorySuggestion {
+	if c.total == 0 {
+		return nil
+	}
+	features := transactionFeatures(transaction)
+	scores := make(map[string]float64, len(c.documents))
+	maxScore := math.Inf(-1)
+	for category, documents := range c.documents {
+		// Log probabilities with add-one smoothing, so unseen features don't zero a category.
+		score := math.Log(float64(documents) / float64(c.total))
+		denominator := float64(c.featureTotals[category] + len(c.vocabulary))
+		for _, feature := range features {
+			score += math.Log(float64(c.features[category][feature]+1) / denominator)
+		}
+		scores[category] = score
+		maxScore = math.Max(maxScore, score)
+	}
+	var sum float64
+	for _, score := range scores {
+		sum += math.Exp(score - maxScore)
+	}
+	suggestions := make([]categorySuggestion, 0, len(scores))
+	for category, score := range scores {
+		suggestions = append(suggestions, categorySuggestion{Category: category, Confidence: math.Exp(score-maxScore) / sum})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Confidence == suggestions[j].Confidence {
+			return suggestions[i].Category < suggestions[j].Category
+		}
+		return suggestions[i].Confidence > suggestions[j].Confidence
+	})
+	if len(suggestions) > n {
+		suggestions = suggestions[:n]
+	}
+	return suggestions
+}
+
+// minAutoDocuments is the number of categorized transactions a category needs before
+// trackit transaction categorize --auto assigns it.
+const minAutoDocuments = 3
+
+// autoCategories returns how many categories have enough categorized transactions to be
+// assigned automatically.
+func (c *categoryClassifier) autoCategories() int {
+	var categories int
+	for _, documents := range c.documents {
+		if documents >= minAutoDocuments {
+			categories++
+		}
+	}
+	return categories
+}
+
+// canAutoAssign reports whether a suggested category can be assigned without asking. The
+// confidence of a suggestion is only relative to the other categories, so it's high even
+// for a transaction unlike any seen. The category must have enough categorized transactions,
+// and one of the words of the transaction's counter party must have been seen in them.
+func (c *categoryClassifier) canAutoAssign(transaction models.TransactionsView, category string) bool {
+	if c.documents[category] < minAutoDocuments {
+		return false
+	}
+	for _, feature := range transactionFeatures(transaction) {
+		if strings.HasPrefix(feature, "word:") && c.features[category][feature] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// transactionFeatures returns the lower case words of the counter party, ignoring numbers
+// and single characters, which are mostly store and reference numbers, a bucket for the
+// amount and the account.
+func transactionFeatures(transaction models.TransactionsView) []string {
+	var features []string
+	words := strings.FieldsFunc(strings.ToLower(transaction.CounterParty), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if len([]rune(word)) < 2 || strings.IndexFunc(word, unicode.IsLetter) == -1 {
+			continue
+		}
+		features = append(features, "word:"+word)
+	}
+	sign := "+"
+	if transaction.Amount < 0 {
+		sign = "-"
+	}
+	magnitude := 0
+	if amount := math.Abs(transaction.Amount); amount >= 1 {
+		magnitude = int(math.Log10(amount))
+	}
+	features = append(features, fmt.Sprintf("amount:%s%d", sign, magnitude))
+	if transaction.AccountName.Valid {
+		features = append(features, "account:"+transaction.AccountName.String)
+	}
+	return features
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/kahunacohen/trackit/internal/models"
+)
+
+func testTransaction(counterParty string, amount float64, account string) models.TransactionsView {
+	return models.TransactionsView{
+		CounterParty: counterParty,
+		Amount:       amount,
+		AccountName:  sql.NullString{Valid: account != "", String: account},
+	}
+}
+
+// trainedClassifier returns a classifier trained on a few groceries and fuel transactions.
+func trainedClassifier() *categoryClassifier {
+	c := &categoryClassifier{
+		documents:     make(map[string]int),
+		features:      make(map[string]map[string]int),
+		featureTotals: make(map[string]int),
+		vocabulary:    make(map[string]bool),
+	}
+	for _, transaction := range []models.TransactionsView{
+		testTransaction("SHUFERSAL DEAL 123", -254.10, "visa"),
+		testTransaction("SHUFERSAL ONLINE", -412.00, "visa"),
+		testTransaction("RAMI LEVY", -180.35, "visa"),
+	} {
+		c.train("Groceries", transactionFeatures(transaction))
+	}
+	for _, transaction := range []models.TransactionsView{
+		testTransaction("PAZ YELLOW", -250.00, "visa"),
+		testTransaction("SONOL", -300.00, "visa"),
+	} {
+		c.train("Fuel", transactionFeatures(transaction))
+	}
+	return c
+}
+
+func TestTransactionFeatures(t *testing.T) {
+	tests := []struct {
+		transaction models.TransactionsView
+		features    []string
+	}{
+		{testTransaction("SHUFERSAL DEAL #123", -254.10, "visa"), []string{"word:shufersal", "word:deal", "amount:-2", "account:visa"}},
+		{testTransaction("Café-Noir A 7", 0.5, ""), []string{"word:café", "word:noir", "amount:+0"}},
+		{testTransaction("", 12000, "checking"), []string{"amount:+4", "account:checking"}},
+		{testTransaction("7-ELEVEN 2k4", -9.99, ""), []string{"word:eleven", "word:2k4", "amount:-0"}},
+	}
+	for _, test := range tests {
+		if features := transactionFeatures(test.transaction); !reflect.DeepEqual(features, test.features) {
+			t.Errorf("transactionFeatures(%q, %v) = %q, want %q", test.transaction.CounterParty, test.transaction.Amount,
+				features, test.features)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	c := trainedClassifier()
+	tests := []struct {
+		transaction models.TransactionsView
+		category    string
+	}{
+		{testTransaction("SHUFERSAL EXPRESS", -95.00, "visa"), "Groceries"},
+		{testTransaction("RAMI LEVY HASHIKMA", -320.00, "visa"), "Groceries"},
+		{testTransaction("PAZ STATION", -260.00, "visa"), "Fuel"},
+	}
+	for _, test := range tests {
+		suggestions := c.suggest(test.transaction, 2)
+		if len(suggestions) != 2 {
+			t.Errorf("suggest(%q) returned %d suggestions, want 2", test.transaction.CounterParty, len(suggestions))
+			continue
+		}
+		if suggestions[0].Category != test.category {
+			t.Errorf("suggest(%q) = %v, want %s first", test.transaction.CounterParty, suggestions, test.category)
+		}
+		if sum := suggestions[0].Confidence + suggestions[1].Confidence; sum < 0.999 || sum > 1.001 {
+			t.Errorf("suggest(%q) confidences sum to %v, want 1", test.transaction.CounterParty, sum)
+		}
+	}
+	if suggestions := c.suggest(testTransaction("SHUFERSAL", -10, ""), 1); len(suggestions) != 1 {
+		t.Errorf("suggest with n 1 returned %d suggestions", len(suggestions))
+	}
+	empty := &categoryClassifier{}
+	if suggestions := empty.suggest(testTransaction("SHUFERSAL", -10, ""), 3); suggestions != nil {
+		t.Errorf("suggest without training = %v, want nil", suggestions)
+	}
+}
+
+func TestCanAutoAssign(t *testing.T) {
+	c := trainedClassifier()
+	tests := []struct {
+		transaction models.TransactionsView
+		category    string
+		assign      bool
+	}{
+		{testTransaction("SHUFERSAL EXPRESS", -95.00, "visa"), "Groceries", true},
+		// Only the amount and account were seen, not a word of the counter party.
+		{testTransaction("AMAZON", -254.10, "visa"), "Groceries", false},
+		// Fuel has fewer than minAutoDocuments transactions.
+		{testTransaction("PAZ STATION", -260.00, "visa"), "Fuel", false},
+		{testTransaction("SHUFERSAL", -95.00, "visa"), "Dining", false},
+	}
+	for _, test := range tests {
+		if assign := c.canAutoAssign(test.transaction, test.category); assign != test.assign {
+			t.Errorf("canAutoAssign(%q, %s) = %v, want %v", test.transaction.CounterParty, test.category, assign, test.assign)
+		}
+	}
+	if categories := c.autoCategories(); categories != 1 {
+		t.Errorf("autoCategories() = %d, want 1", categories)
+	}
+}
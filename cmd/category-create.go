@@ -17,8 +17,24 @@ var categoryCreateCmd = &cobra.Command{
 	Aliases: []string{"add"},
 	Args:    cobra.ExactArgs(1),
 	Short:   "Creates a category. categories add <name>",
-	Long:    `Creates a category, taking one positional argument (category name). categories add <name>`,
+	Long: `Creates a category, taking one positional argument (category name). categories add <name>
+
+To create a child category, pass the parent with --parent, or separate the names with a colon.
+Parents that don't exist yet are created. E.g.:
+
+$ trackit category add Fuel --parent Transportation
+$ trackit category add Transportation:Parking
+
+Child categories get the kind of their parent. Pass --kind for other categories that aren't
+expenses:
+
+$ trackit category add Salary --kind income`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		parent, _ := cmd.Flags().GetString("parent")
+		path := args[0]
+		if parent != "" {
+			path = parent + categoryPathSeparator + path
+		}
 		_, _, dbPath, err := getDataPaths()
 		if err != nil {
 			return err
@@ -29,14 +45,20 @@ var categoryCreateCmd = &cobra.Command{
 		}
 		ctx := context.Background()
 		queries := models.New(db)
-		err = queries.CreateCategory(ctx, args[0])
+		id, err := createCategoryPath(ctx, queries, path)
 		if err != nil {
 			return fmt.Errorf("error creating category: %w", err)
 		}
+		if cmd.Flags().Changed("kind") {
+			kind, _ := cmd.Flags().GetString("kind")
+			return setCategoryKind(ctx, queries, id, kind)
+		}
 		return nil
 	},
 }
 
 func init() {
 	categoryCmd.AddCommand(categoryCreateCmd)
+	categoryCreateCmd.Flags().StringP("parent", "p", "", "Name of the parent category")
+	categoryCreateCmd.Flags().StringP("kind", "k", "expense", "Kind of category: income, expense, transfer or savings. Transfers aren't counted as income or spending. Child categories default to their parent's kind")
 }
@@ -5,9 +5,8 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"strconv"
 
 	"github.com/kahunacohen/trackit/internal/models"
 	"github.com/spf13/cobra"
@@ -17,31 +16,88 @@ var categoryDeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Args:  cobra.ExactArgs(1),
 	Short: "Deletes a category. delete <id>",
-	Long: `Deletes an existing category by ID. trackit categories delete <id>. Get the category
-id by doing trackit categories list`,
+	Long: `Deletes an existing category by ID or name. trackit categories delete <id>. Get the category
+id by doing trackit categories list.
+
+A category with transactions, splits, rules or child categories is only deleted if you say what happens to them: --reassign <id>
+moves them, and the category's rules and child categories, to another category, while --uncategorize
+leaves them un-categorized, deletes the category's rules and moves its child categories to the top
+level. How many of each are affected is printed first, and --dry-run only prints it. E.g.:
+
+$ trackit category delete Supermarket --reassign Groceries
+$ trackit category delete 12 --uncategorize`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		reassign, _ := cmd.Flags().GetString("reassign")
+		uncategorize, _ := cmd.Flags().GetBool("uncategorize")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		if reassign != "" && uncategorize {
+			return errors.New("--reassign and --uncategorize can't be passed together")
+		}
 		_, _, dbPath, err := getDataPaths()
 		if err != nil {
 			return err
 		}
 		db, err := getDB(dbPath)
 		if err != nil {
-			log.Fatalf("Failed to open database: %v", err)
+			return err
 		}
+		defer db.Close()
 		ctx := context.Background()
-		queries := models.New(db)
-		id, err := strconv.ParseInt(args[0], 10, 64)
+		tx, err := db.Begin()
 		if err != nil {
-			return fmt.Errorf("error parsing id: %w", err)
+			return fmt.Errorf("error beginning db transaction: %w", err)
 		}
-		err = queries.DeleteCategory(ctx, id)
+		defer tx.Rollback()
+		queries := models.New(tx)
+		tree, err := readCategoryTree(ctx, queries)
 		if err != nil {
+			return err
+		}
+		id, err := tree.categoryId(args[0])
+		if err != nil {
+			return err
+		}
+		usage, err := queries.ReadCategoryUsage(ctx, id)
+		if err != nil {
+			return fmt.Errorf("error counting the transactions of category %s: %w", tree.names[id], err)
+		}
+		var reassignId int64
+		action := "un-categorized, rules deleted and child categories moved to the top level"
+		if reassign != "" {
+			if reassignId, err = tree.categoryId(reassign); err != nil {
+				return err
+			}
+			if err := validateCategoryMove(tree, id, reassignId); err != nil {
+				return err
+			}
+			action = fmt.Sprintf("reassigned to %s", tree.names[reassignId])
+		}
+		if err := printCategoryUsage(ctx, queries, tree, id, action); err != nil {
+			return err
+		}
+		if reassign == "" && !uncategorize && usage.TransactionCount+usage.SplitCount+usage.RuleCount+usage.ChildCount > 0 {
+			return fmt.Errorf("category %s is in use. Pass --reassign <id> to move its transactions, rules and child categories to another category, or --uncategorize", tree.names[id])
+		}
+		if dryRun {
+			return nil
+		}
+		if reassign != "" {
+			if err := moveCategory(ctx, queries, tree, id, reassignId); err != nil {
+				return err
+			}
+		}
+		// Foreign keys un-categorize the transactions and splits left, delete the rules and
+		// move the child categories to the top level.
+		if err := queries.DeleteCategory(ctx, id); err != nil {
 			return fmt.Errorf("error deleting category: %w", err)
 		}
-		return nil
+		return tx.Commit()
 	},
 }
 
 func init() {
 	categoryCmd.AddCommand(categoryDeleteCmd)
+	categoryDeleteCmd.Flags().StringP("reassign", "r", "", "ID or name of the category to move the category's transactions to")
+	categoryDeleteCmd.Flags().BoolP("uncategorize", "u", false, "Leave the category's transactions un-categorized")
+	categoryDeleteCmd.Flags().Bool("dry-run", false, "Only print how many transactions, splits, rules and child categories would be affected")
 }
@@ -5,11 +5,9 @@ package cmd
 
 import (
 	"context"
-	"fmt"
 	"log"
-	"os"
+	"strings"
 
-	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/kahunacohen/trackit/internal/models"
 	"github.com/spf13/cobra"
 )
@@ -18,7 +16,7 @@ var categoryListCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "lists existing categories",
-	Long:    `lists existing categories. trackit category list`,
+	Long:    `lists existing categories, with child categories under their parents. trackit category list`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		_, _, dbPath, err := getDataPaths()
 		if err != nil {
@@ -30,22 +28,44 @@ var categoryListCmd = &cobra.Command{
 		}
 		ctx := context.Background()
 		queries := models.New(db)
-		categories, err := queries.ReadAllCategories(ctx)
+		tree, err := readCategoryTree(ctx, queries)
 		if err != nil {
-			return fmt.Errorf("error getting categories: %w", err)
-		}
-		t := table.NewWriter()
-		t.SetStyle(table.StyleLight)
-		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"ID", "Name"})
-		for _, category := range categories {
-			t.AppendRow([]interface{}{category.ID, category.Name})
+			return err
 		}
-		t.Render()
-		return nil
+		r := report{columns: []outputColumn{{key: "id", header: "ID"}, {header: "Name"}, {key: "name"}, {key: "parent"}, {key: "kind", header: "Kind"}}}
+		tree.walk(func(category models.Category, last []bool) {
+			var parent any
+			if category.ParentID.Valid {
+				parent = tree.names[category.ParentID.Int64]
+			}
+			r.rows = append(r.rows, []any{category.ID, categoryTreePrefix(last) + category.Name, category.Name, parent, category.Kind})
+		})
+		return printReport(r)
 	},
 }
 
 func init() {
 	categoryCmd.AddCommand(categoryListCmd)
 }
+
+// categoryTreePrefix returns the lines drawn before the name of a category in the tree,
+// given whether it and each of its ancestors is the last of its siblings.
+func categoryTreePrefix(last []bool) string {
+	if len(last) < 2 {
+		return ""
+	}
+	var prefix strings.Builder
+	for _, ancestorLast := range last[1 : len(last)-1] {
+		if ancestorLast {
+			prefix.WriteString("   ")
+		} else {
+			prefix.WriteString("│  ")
+		}
+	}
+	if last[len(last)-1] {
+		prefix.WriteString("└─ ")
+	} else {
+		prefix.WriteString("├─ ")
+	}
+	return prefix.String()
+}
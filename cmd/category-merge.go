@@ -0,0 +1,129 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var categoryMergeCmd = &cobra.Command{
+	Use:   "merge",
+	Args:  cobra.MinimumNArgs(2),
+	Short: "Merges categories into another. trackit category merge <from> [<from>...] <into>",
+	Long: `Merges categories, by name or ID, into another category. The transactions, splits, rules and
+child categories of the categories merged from are moved to the category merged into, and the
+categories merged from are deleted. How many of each are moved is printed first, and --dry-run
+only prints it. E.g.:
+
+$ trackit category merge Supermarket "Food Shopping" Groceries`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		ctx := context.Background()
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("error beginning db transaction: %w", err)
+		}
+		defer tx.Rollback()
+		queries := models.New(tx)
+		tree, err := readCategoryTree(ctx, queries)
+		if err != nil {
+			return err
+		}
+		intoId, err := tree.categoryId(args[len(args)-1])
+		if err != nil {
+			return err
+		}
+		for _, arg := range args[:len(args)-1] {
+			fromId, err := tree.categoryId(arg)
+			if err != nil {
+				return err
+			}
+			if err := validateCategoryMove(tree, fromId, intoId); err != nil {
+				return err
+			}
+			if err := printCategoryUsage(ctx, queries, tree, fromId, fmt.Sprintf("into %s", tree.names[intoId])); err != nil {
+				return err
+			}
+			if dryRun {
+				continue
+			}
+			if err := moveCategory(ctx, queries, tree, fromId, intoId); err != nil {
+				return err
+			}
+			if err := queries.DeleteCategory(ctx, fromId); err != nil {
+				return fmt.Errorf("error deleting category %s: %w", tree.names[fromId], err)
+			}
+		}
+		if dryRun {
+			return nil
+		}
+		return tx.Commit()
+	},
+}
+
+func init() {
+	categoryCmd.AddCommand(categoryMergeCmd)
+	categoryMergeCmd.Flags().Bool("dry-run", false, "Only print how many transactions, splits, rules and child categories would be moved")
+}
+
+// printCategoryUsage prints how many transactions, splits, rules and child categories of a
+// category are affected by merging or deleting it.
+func printCategoryUsage(ctx context.Context, queries *models.Queries, tree *categoryTree, id int64, action string) error {
+	usage, err := queries.ReadCategoryUsage(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error counting the transactions of category %s: %w", tree.names[id], err)
+	}
+	fmt.Printf("%s: %d transactions, %d splits, %d rules and %d child categories %s\n", tree.names[id],
+		usage.TransactionCount, usage.SplitCount, usage.RuleCount, usage.ChildCount, action)
+	return nil
+}
+
+// validateCategoryMove checks that a category can be moved to another, which must not be
+// the category itself or one of its children.
+func validateCategoryMove(tree *categoryTree, fromId int64, intoId int64) error {
+	from, into := tree.names[fromId], tree.names[intoId]
+	if fromId == intoId {
+		return fmt.Errorf("can't merge category %s into itself", from)
+	}
+	if slices.Contains(tree.ancestors(into), from) {
+		return fmt.Errorf("can't merge category %s into its own child %s", from, into)
+	}
+	return nil
+}
+
+// moveCategory moves the transactions, splits, rules and child categories of a category to
+// another.
+func moveCategory(ctx context.Context, queries *models.Queries, tree *categoryTree, fromId int64, intoId int64) error {
+	from := tree.names[fromId]
+	fromID := sql.NullInt64{Valid: true, Int64: fromId}
+	intoID := sql.NullInt64{Valid: true, Int64: intoId}
+	if err := queries.MoveCategoryTransactions(ctx, models.MoveCategoryTransactionsParams{IntoID: intoID, FromID: fromID}); err != nil {
+		return fmt.Errorf("error moving transactions of category %s: %w", from, err)
+	}
+	if err := queries.MoveCategorySplits(ctx, models.MoveCategorySplitsParams{IntoID: intoID, FromID: fromID}); err != nil {
+		return fmt.Errorf("error moving splits of category %s: %w", from, err)
+	}
+	if err := queries.MoveCategoryRules(ctx, models.MoveCategoryRulesParams{IntoID: intoID, FromID: fromID}); err != nil {
+		return fmt.Errorf("error moving rules of category %s: %w", from, err)
+	}
+	if err := queries.MoveCategoryChildren(ctx, models.MoveCategoryChildrenParams{IntoID: intoID, FromID: fromID}); err != nil {
+		return fmt.Errorf("error moving child categories of category %s: %w", from, err)
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kahunacohen/trackit/internal/config"
+	"github.com/kahunacohen/trackit/internal/models"
+)
+
+// categoryPathSeparator separates parent and child category names in trackit.yaml and on
+// the command line, e.g. Transportation:Fuel.
+const categoryPathSeparator = ":"
+
+// splitCategoryPath splits a category path like Transportation:Fuel into its names, top
+// level first.
+func splitCategoryPath(path string) []string {
+	var names []string
+	for _, name := range strings.Split(path, categoryPathSeparator) {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// categoryLeafName returns the name of the category a path like Transportation:Fuel refers
+// to. Category names are unique, so the last name identifies the category.
+func categoryLeafName(path string) string {
+	names := splitCategoryPath(path)
+	if len(names) == 0 {
+		return path
+	}
+	return names[len(names)-1]
+}
+
+// createCategoryPath creates the categories of a path like Transportation:Fuel that don't
+// exist yet, sets each one's parent to the one before it, and returns the ID of the last.
+// The first category's parent is left as is. Child categories that are created get the kind
+// of their parent, so that e.g. Income:Salary is income.
+func createCategoryPath(ctx context.Context, queries *models.Queries, path string) (int64, error) {
+	names := splitCategoryPath(path)
+	if len(names) == 0 {
+		return 0, fmt.Errorf("invalid category name '%s'", path)
+	}
+	tree, err := readCategoryTree(ctx, queries)
+	if err != nil {
+		return 0, err
+	}
+	kinds := tree.kinds()
+	var parentId int64
+	for i, name := range names {
+		_, exists := tree.ids[name]
+		if err := queries.CreateCategory(ctx, name); err != nil {
+			return 0, fmt.Errorf("error creating category %s: %w", name, err)
+		}
+		id, err := queries.ReadCategoryIdByName(ctx, name)
+		if err != nil {
+			return 0, fmt.Errorf("error getting category ID for %s: %w", name, err)
+		}
+		if i > 0 {
+			if err := setCategoryParent(ctx, queries, tree, id, parentId); err != nil {
+				return 0, err
+			}
+			tree.parents[id] = parentId
+			if !exists {
+				kinds[name] = kinds[names[i-1]]
+				if err := setCategoryKind(ctx, queries, id, kinds[name]); err != nil {
+					return 0, err
+				}
+			}
+		} else if !exists {
+			kinds[name] = "expense"
+		}
+		parentId = id
+	}
+	return parentId, nil
+}
+
+// createConfigCategories creates the categories in trackit.yaml that aren't in the database
+// yet, and returns their paths. Categories that already exist are left where they are.
+func createConfigCategories(ctx context.Context, queries *models.Queries, conf *config.Config) ([]string, error) {
+	if conf == nil {
+		return nil, nil
+	}
+	tree, err := readCategoryTree(ctx, queries)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(conf.Categories))
+	for path := range conf.Categories {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	var created []string
+	for _, path := range paths {
+		if _, ok := tree.ids[categoryLeafName(path)]; ok {
+			continue
+		}
+		if _, err := createCategoryPath(ctx, queries, path); err != nil {
+			return nil, err
+		}
+		created = append(created, path)
+	}
+	return created, nil
+}
+
+// setCategoryParent moves a category under another, or to the top level if parentId is 0.
+func setCategoryParent(ctx context.Context, queries *models.Queries, tree *categoryTree, id int64, parentId int64) error {
+	for ancestor := parentId; ancestor != 0; ancestor = tree.parents[ancestor] {
+		if ancestor == id {
+			return fmt.Errorf("category %s can't be a child of itself or of its own children", tree.names[id])
+		}
+	}
+	err := queries.UpdateCategoryParent(ctx, models.UpdateCategoryParentParams{
+		ParentID: sql.NullInt64{Valid: parentId != 0, Int64: parentId},
+		ID:       id})
+	if err != nil {
+		return fmt.Errorf("error setting parent of category %d: %w", id, err)
+	}
+	return nil
+}
+
+// categoryKinds are the kinds of category. The kind decides which total a category's
+// amounts count towards.
+var categoryKinds = []string{"income", "expense", "transfer", "savings"}
+
+// setCategoryKind sets the kind of a category.
+func setCategoryKind(ctx context.Context, queries *models.Queries, id int64, kind string) error {
+	if !slices.Contains(categoryKinds, kind) {
+		return fmt.Errorf("invalid kind '%s'. Must be one of: %s", kind, strings.Join(categoryKinds, ", "))
+	}
+	if err := queries.UpdateCategoryKind(ctx, models.UpdateCategoryKindParams{Kind: kind, ID: id}); err != nil {
+		return fmt.Errorf("error setting kind of category %d: %w", id, err)
+	}
+	return nil
+}
+
+// categoryTree is the hierarchy of categories.
+type categoryTree struct {
+	categories []models.Category
+	names      map[int64]string
+	ids        map[string]int64
+	// parents maps category IDs to their parent's ID. Top level categories aren't in it.
+	parents  map[int64]int64
+	children map[int64][]models.Category
+}
+
+func readCategoryTree(ctx context.Context, queries *models.Queries) (*categoryTree, error) {
+	categories, err := queries.ReadAllCategories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting categories: %w", err)
+	}
+	tree := &categoryTree{
+		categories: categories,
+		names:      make(map[int64]string),
+		ids:        make(map[string]int64),
+		parents:    make(map[int64]int64),
+		children:   make(map[int64][]models.Category),
+	}
+	for _, category := range categories {
+		tree.names[category.ID] = category.Name
+		tree.ids[category.Name] = category.ID
+	}
+	// Categories are sorted by name, so children are too.
+	for _, category := range categories {
+		if _, ok := tree.names[category.ParentID.Int64]; ok && category.ParentID.Valid {
+			tree.parents[category.ID] = category.ParentID.Int64
+		}
+		parentId := tree.parents[category.ID]
+		tree.children[parentId] = append(tree.children[parentId], category)
+	}
+	return tree, nil
+}
+
+// categoryId returns the ID of a category by its name, path like Transportation:Fuel, or ID.
+func (t *categoryTree) categoryId(category string) (int64, error) {
+	if id, ok := t.ids[categoryLeafName(category)]; ok {
+		return id, nil
+	}
+	if id, err := strconv.ParseInt(category, 10, 64); err == nil {
+		if _, ok := t.names[id]; ok {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("category %s doesn't exist. Do trackit category list to see existing categories", category)
+}
+
+// ancestors returns the category and its ancestors, top level first.
+func (t *categoryTree) ancestors(name string) []string {
+	id, ok := t.ids[name]
+	if !ok {
+		return []string{name}
+	}
+	var names []string
+	seen := make(map[int64]bool)
+	for ; id != 0 && !seen[id]; id = t.parents[id] {
+		seen[id] = true
+		names = append([]string{t.names[id]}, names...)
+	}
+	return names
+}
+
+// descendants returns the name of a category and of all its descendants.
+func (t *categoryTree) descendants(id int64) []string {
+	names := []string{t.names[id]}
+	for _, child := range t.children[id] {
+		names = append(names, t.descendants(child.ID)...)
+	}
+	return names
+}
+
+// rollUp returns the ancestor at depth (1 is the top level) of a category, or the category
+// itself if it's at depth or above.
+func (t *categoryTree) rollUp(name string, depth int) string {
+	ancestors := t.ancestors(name)
+	if len(ancestors) > depth {
+		return ancestors[depth-1]
+	}
+	return name
+}
+
+// walk calls f for every category, parents before their children, with whether the category
+// and each of its ancestors is the last of its siblings, top level first.
+func (t *categoryTree) walk(f func(category models.Category, last []bool)) {
+	var visit func(parentId int64, last []bool)
+	visit = func(parentId int64, last []bool) {
+		children := t.children[parentId]
+		for i, child := range children {
+			childLast := append(append([]bool{}, last...), i == len(children)-1)
+			f(child, childLast)
+			visit(child.ID, childLast)
+		}
+	}
+	visit(0, nil)
+}
+
+// rollUpAggregation adds the totals of categories deeper than depth to their ancestor at
+// depth.
+func (t *categoryTree) rollUpAggregation(rows []models.AggregateTransactionsRow, depth int) []models.AggregateTransactionsRow {
+	totals := make(map[string]float64)
+	for _, row := range rows {
+		totals[t.rollUp(row.CategoryName, depth)] += row.TotalAmount
+	}
+	return sortedAggregation(totals)
+}
+
+// kinds maps category names to their kind.
+func (t *categoryTree) kinds() map[string]string {
+	kinds := make(map[string]string, len(t.categories))
+	for _, category := range t.categories {
+		kinds[category.Name] = category.Kind
+	}
+	return kinds
+}
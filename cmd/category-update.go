@@ -5,6 +5,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 
@@ -14,12 +15,27 @@ import (
 
 var categoryUpdateCmd = &cobra.Command{
 	Use:   "update",
-	Args:  cobra.ExactArgs(2),
+	Args:  cobra.RangeArgs(1, 2),
 	Short: "Updates an existing category. trackit category update <old> <new>",
-	Long:  `Updates an existing category. trackit category update <old> <new>`,
+	Long: `Updates an existing category. trackit category update <old> <new>
+
+To move a category under another, pass the new parent with --parent. An empty parent moves it
+to the top level. E.g.:
+
+$ trackit category update Fuel --parent Transportation
+$ trackit category update Fuel --parent ""
+
+To change the kind of a category:
+
+$ trackit category update "Credit Card Payments" --kind transfer`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fromCat := args[0]
-		toCat := args[1]
+		toCat := fromCat
+		if len(args) == 2 {
+			toCat = args[1]
+		} else if !cmd.Flags().Changed("parent") && !cmd.Flags().Changed("kind") {
+			return errors.New("pass the new category name, --parent or --kind")
+		}
 		_, _, dbPath, err := getDataPaths()
 		if err != nil {
 			return err
@@ -30,6 +46,35 @@ var categoryUpdateCmd = &cobra.Command{
 		}
 		ctx := context.Background()
 		queries := models.New(db)
+		tree, err := readCategoryTree(ctx, queries)
+		if err != nil {
+			return err
+		}
+		id, ok := tree.ids[fromCat]
+		if !ok {
+			return fmt.Errorf("no category named %s. Do trackit category list to see existing categories", fromCat)
+		}
+		if cmd.Flags().Changed("kind") {
+			kind, _ := cmd.Flags().GetString("kind")
+			if err := setCategoryKind(ctx, queries, id, kind); err != nil {
+				return err
+			}
+		}
+		if cmd.Flags().Changed("parent") {
+			parent, _ := cmd.Flags().GetString("parent")
+			var parentId int64
+			if parent != "" {
+				if parentId, ok = tree.ids[parent]; !ok {
+					return fmt.Errorf("no category named %s. Do trackit category list to see existing categories", parent)
+				}
+			}
+			if err := setCategoryParent(ctx, queries, tree, id, parentId); err != nil {
+				return err
+			}
+		}
+		if toCat == fromCat {
+			return nil
+		}
 		err = queries.UpdateCategory(ctx, models.UpdateCategoryParams{
 			Newcategory: toCat, Oldcategory: fromCat})
 		if err != nil {
@@ -41,4 +86,6 @@ var categoryUpdateCmd = &cobra.Command{
 
 func init() {
 	categoryCmd.AddCommand(categoryUpdateCmd)
+	categoryUpdateCmd.Flags().StringP("parent", "p", "", "Name of the new parent category")
+	categoryUpdateCmd.Flags().StringP("kind", "k", "", "New kind of category: income, expense, transfer or savings")
 }
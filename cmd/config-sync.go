@@ -0,0 +1,155 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/kahunacohen/trackit/internal/config"
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var configSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Syncs the categories in trackit.yaml and the database",
+	Long: `Syncs the categories in trackit.yaml and the database. Categories in trackit.yaml that aren't
+in the database are created, and categories in the database that aren't in trackit.yaml, e.g. ones
+created with trackit category create, are listed. trackit's default categories are left out, until
+they have transactions, rules or child categories.
+
+Pass --write to add them to trackit.yaml, along with the payee regular expressions of rules that only
+set a category from the payee (trackit rule add --payee <regexp> --category <name>). Comments and
+the order of keys in trackit.yaml are kept.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		write, _ := cmd.Flags().GetBool("write")
+		_, configPath, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		conf, err := config.ParseConfig(configPath)
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		ctx := context.Background()
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("error beginning db transaction: %w", err)
+		}
+		defer tx.Rollback()
+		queries := models.New(tx)
+		created, err := createConfigCategories(ctx, queries, conf)
+		if err != nil {
+			return err
+		}
+		for _, path := range created {
+			fmt.Printf("created category %s from trackit.yaml\n", path)
+		}
+		tree, err := readCategoryTree(ctx, queries)
+		if err != nil {
+			return err
+		}
+		rules, err := queries.ReadAllRules(ctx)
+		if err != nil {
+			return fmt.Errorf("error reading rules: %w", err)
+		}
+		// Config keys by category name, e.g. Fuel: Transportation:Fuel.
+		configKeys := make(map[string]string)
+		for path := range conf.Categories {
+			configKeys[categoryLeafName(path)] = path
+		}
+		additions := make(map[string][]string)
+		var orphans []models.Category
+		var orphanUsage []models.ReadCategoryUsageRow
+		for _, category := range tree.categories {
+			if _, ok := configKeys[category.Name]; ok {
+				continue
+			}
+			usage, err := queries.ReadCategoryUsage(ctx, category.ID)
+			if err != nil {
+				return fmt.Errorf("error counting the transactions of category %s: %w", category.Name, err)
+			}
+			if slices.Contains(defaultCategories, category.Name) &&
+				usage.TransactionCount+usage.SplitCount+usage.RuleCount+usage.ChildCount == 0 {
+				continue
+			}
+			orphans = append(orphans, category)
+			orphanUsage = append(orphanUsage, usage)
+			configKeys[category.Name] = strings.Join(tree.ancestors(category.Name), categoryPathSeparator)
+			additions[configKeys[category.Name]] = nil
+		}
+		var patterns int
+		for _, rule := range rules {
+			if !isPayeeCategoryRule(rule) {
+				continue
+			}
+			path := configKeys[rule.CategoryName.String]
+			if !slices.Contains(conf.Categories[path], rule.CounterParty.String) && !slices.Contains(additions[path], rule.CounterParty.String) {
+				additions[path] = append(additions[path], rule.CounterParty.String)
+				patterns++
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("error committing categories: %w", err)
+		}
+		if len(orphans) > 0 {
+			fmt.Println("categories in the database but not in trackit.yaml:")
+			t := table.NewWriter()
+			t.SetStyle(table.StyleLight)
+			t.SetOutputMirror(os.Stdout)
+			t.AppendHeader(table.Row{"ID", "Category", "Transactions", "Rules"})
+			for i, category := range orphans {
+				usage := orphanUsage[i]
+				t.AppendRow(table.Row{category.ID, configKeys[category.Name], usage.TransactionCount, usage.RuleCount})
+			}
+			t.Render()
+		}
+		if len(additions) == 0 {
+			fmt.Println("trackit.yaml and the database have the same categories")
+			return nil
+		}
+		if !write {
+			fmt.Printf("pass --write to add %d categories and %d payee regular expressions to trackit.yaml\n", len(orphans), patterns)
+			return nil
+		}
+		if err := config.AddCategories(configPath, additions); err != nil {
+			return err
+		}
+		fmt.Printf("added %d categories and %d payee regular expressions to trackit.yaml\n", len(orphans), patterns)
+		return nil
+	},
+}
+
+// defaultCategories are the categories the migrations create in every database. Unused ones
+// aren't added to trackit.yaml, which would otherwise list all of them.
+var defaultCategories = []string{
+	"Business Expenses", "Childcare", "Clothing", "Debt Payments", "Dining Out", "Donations", "Education",
+	"Entertainment", "Gifts", "Groceries", "Healthcare", "Hobbies", "Home Improvement", "Household Supplies",
+	"Income", "Insurance", "Investments", "Miscellaneous", "Mortgage/Rent", "Personal Care", "Pet Care",
+	"Professional Development", "Savings", "Special Occasions", "Sports & Fitness", "Subscriptions", "Taxes",
+	"Transfers", "Transportation", "Travel", "Utilities",
+}
+
+func init() {
+	configCmd.AddCommand(configSyncCmd)
+	configSyncCmd.Flags().BoolP("write", "w", false, "Add the categories, and payee rules, that are only in the database to trackit.yaml")
+}
+
+// isPayeeCategoryRule reports whether a rule only sets a category from the payee, like the
+// regular expressions of categories in trackit.yaml.
+func isPayeeCategoryRule(rule models.ReadAllRulesRow) bool {
+	return rule.CounterParty.Valid && rule.CategoryName.Valid && !rule.Description.Valid && !rule.AccountID.Valid &&
+		!rule.MinAmount.Valid && !rule.MaxAmount.Valid && !rule.Sign.Valid && !rule.FromDate.Valid &&
+		!rule.ToDate.Valid && !rule.IgnoreWhenSumming.Valid && !rule.Tags.Valid
+}
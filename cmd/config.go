@@ -0,0 +1,21 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manages trackit.yaml",
+	Long:  `Manages trackit.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+}
@@ -7,9 +7,7 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 
-	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/kahunacohen/trackit/internal/models"
 	"github.com/spf13/cobra"
 )
@@ -35,15 +33,11 @@ var currencyListCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("error reading currency codes: %w", err)
 		}
-		t := table.NewWriter()
-		t.SetStyle(table.StyleLight)
-		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"ID", "Name"})
+		r := report{columns: []outputColumn{{key: "id", header: "ID"}, {key: "symbol", header: "Name"}}}
 		for _, currency := range currencies {
-			t.AppendRow([]interface{}{currency.ID, currency.Symbol})
+			r.rows = append(r.rows, []any{currency.ID, currency.Symbol})
 		}
-		t.Render()
-		return nil
+		return printReport(r)
 	},
 }
 
@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// filterFields maps the fields of the filter language to the transactions_view expressions
+// they compare, and whether they are numeric.
+var filterFields = map[string]struct {
+	column  string
+	numeric bool
+}{
+	"id":            {"transaction_id", true},
+	"date":          {"date", false},
+	"account":       {"account_name", false},
+	"payee":         {"COALESCE(payee_name, counter_party)", false},
+	"counter_party": {"counter_party", false},
+	"description":   {"description", false},
+	"amount":        {"amount", true},
+	"category":      {"category_name", false},
+	"kind":          {"category_kind", false},
+	"ignored":       {"ignore_when_summing", true},
+}
+
+// filterOperators are the comparison operators of the filter language. ~ and !~ test
+// whether a field contains, or doesn't contain, a value regardless of case.
+var filterOperators = []string{"!=", "<=", ">=", "!~", "=", "<", ">", "~"}
+
+// filter is a parsed --where filter, as a SQL condition on transactions_view and its
+// arguments. ignored is whether it tests the ignored field.
+type filter struct {
+	where   string
+	args    []any
+	ignored bool
+}
+
+// parseFilter parses a filter such as:
+//
+//	payee ~ "whole foods" and amount < -50 and (category is null or category = Groceries)
+//
+// Conditions compare a field to a value, and are combined with and, or, not and
+// parentheses. Values with spaces must be quoted. tag = <name> matches transactions with
+// the tag.
+func parseFilter(s string) (filter, error) {
+	tokens, err := tokenizeFilter(s)
+	if err != nil {
+		return filter{}, err
+	}
+	p := &filterParser{tokens: tokens}
+	f, err := p.or()
+	if err != nil {
+		return filter{}, err
+	}
+	if p.pos < len(p.tokens) {
+		return filter{}, fmt.Errorf("unexpected '%s' in filter", p.tokens[p.pos].text)
+	}
+	return f, nil
+}
+
+type filterToken struct {
+	text   string
+	quoted bool
+}
+
+func tokenizeFilter(s string) ([]filterToken, error) {
+	var tokens []filterToken
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, filterToken{text: string(r)})
+			i++
+		case r == '"' || r == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated quote in filter: %s", s)
+			}
+			tokens = append(tokens, filterToken{text: string(runes[i+1 : end]), quoted: true})
+			i = end + 1
+		default:
+			if operator := filterOperatorAt(runes[i:]); operator != "" {
+				tokens = append(tokens, filterToken{text: operator})
+				i += len(operator)
+				continue
+			}
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`()"'`, runes[end]) && filterOperatorAt(runes[end:]) == "" {
+				end++
+			}
+			tokens = append(tokens, filterToken{text: string(runes[i:end])})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+func filterOperatorAt(runes []rune) string {
+	for _, operator := range filterOperators {
+		if strings.HasPrefix(string(runes), operator) {
+			return operator
+		}
+	}
+	return ""
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+// keyword consumes the next token if it's the unquoted keyword.
+func (p *filterParser) keyword(keyword string) bool {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && strings.EqualFold(p.tokens[p.pos].text, keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) next() (filterToken, error) {
+	if p.pos == len(p.tokens) {
+		return filterToken{}, fmt.Errorf("filter ends unexpectedly")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *filterParser) or() (filter, error) {
+	return p.join("or", p.and)
+}
+
+func (p *filterParser) and() (filter, error) {
+	return p.join("and", p.unary)
+}
+
+// join parses one or more operands separated by the keyword.
+func (p *filterParser) join(keyword string, operand func() (filter, error)) (filter, error) {
+	f, err := operand()
+	if err != nil {
+		return filter{}, err
+	}
+	for p.keyword(keyword) {
+		right, err := operand()
+		if err != nil {
+			return filter{}, err
+		}
+		f = filter{where: fmt.Sprintf("%s %s %s", f.where, strings.ToUpper(keyword), right.where), args: append(f.args, right.args...),
+			ignored: f.ignored || right.ignored}
+	}
+	return f, nil
+}
+
+func (p *filterParser) unary() (filter, error) {
+	if p.keyword("not") {
+		f, err := p.unary()
+		if err != nil {
+			return filter{}, err
+		}
+		return filter{where: fmt.Sprintf("NOT %s", f.where), args: f.args, ignored: f.ignored}, nil
+	}
+	if p.keyword("(") {
+		f, err := p.or()
+		if err != nil {
+			return filter{}, err
+		}
+		if !p.keyword(")") {
+			return filter{}, fmt.Errorf("missing ')' in filter")
+		}
+		return filter{where: fmt.Sprintf("(%s)", f.where), args: f.args, ignored: f.ignored}, nil
+	}
+	return p.comparison()
+}
+
+func (p *filterParser) comparison() (filter, error) {
+	fieldToken, err := p.next()
+	if err != nil {
+		return filter{}, err
+	}
+	name := strings.ToLower(fieldToken.text)
+	if name == "tag" {
+		return p.tagComparison()
+	}
+	field, ok := filterFields[name]
+	if !ok || fieldToken.quoted {
+		return filter{}, fmt.Errorf("unknown field '%s' in filter. Must be one of: %s", fieldToken.text, strings.Join(filterFieldNames(), ", "))
+	}
+	if p.keyword("is") {
+		condition := "IS NULL"
+		if p.keyword("not") {
+			condition = "IS NOT NULL"
+		}
+		if !p.keyword("null") {
+			return filter{}, fmt.Errorf("expected null after '%s is' in filter", name)
+		}
+		return filter{where: fmt.Sprintf("%s %s", field.column, condition), ignored: name == "ignored"}, nil
+	}
+	operator, err := p.next()
+	if err != nil {
+		return filter{}, err
+	}
+	if operator.quoted || !slices.Contains(filterOperators, operator.text) {
+		return filter{}, fmt.Errorf("expected an operator after '%s' in filter, got '%s'", name, operator.text)
+	}
+	valueToken, err := p.next()
+	if err != nil {
+		return filter{}, err
+	}
+	var value any = valueToken.text
+	if field.numeric && operator.text != "~" && operator.text != "!~" {
+		if value, err = filterNumber(name, valueToken.text); err != nil {
+			return filter{}, err
+		}
+	}
+	switch operator.text {
+	case "~":
+		return filter{where: fmt.Sprintf(`%s LIKE '%%' || ? || '%%' ESCAPE '\'`, field.column), args: []any{escapeLike(valueToken.text)},
+			ignored: name == "ignored"}, nil
+	case "!~":
+		return filter{where: fmt.Sprintf(`(%s IS NULL OR %s NOT LIKE '%%' || ? || '%%' ESCAPE '\')`, field.column, field.column),
+			args: []any{escapeLike(valueToken.text)}, ignored: name == "ignored"}, nil
+	case "!=":
+		return filter{where: fmt.Sprintf("%s IS NOT ?", field.column), args: []any{value}, ignored: name == "ignored"}, nil
+	}
+	return filter{where: fmt.Sprintf("%s %s ?", field.column, operator.text), args: []any{value}, ignored: name == "ignored"}, nil
+}
+
+func (p *filterParser) tagComparison() (filter, error) {
+	operator, err := p.next()
+	if err != nil {
+		return filter{}, err
+	}
+	if operator.quoted || (operator.text != "=" && operator.text != "!=") {
+		return filter{}, fmt.Errorf("tag can only be compared with = or != in filter")
+	}
+	value, err := p.next()
+	if err != nil {
+		return filter{}, err
+	}
+	condition := "IN"
+	if operator.text == "!=" {
+		condition = "NOT IN"
+	}
+	return filter{
+		where: fmt.Sprintf("transaction_id %s (SELECT transaction_tags.transaction_id FROM transaction_tags JOIN tags ON tags.id = transaction_tags.tag_id WHERE tags.name = ?)", condition),
+		args:  []any{value.text},
+	}, nil
+}
+
+// filterNumber parses the value of a numeric field. ignored also takes true and false.
+func filterNumber(name string, value string) (any, error) {
+	if name == "ignored" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			if b {
+				return 1, nil
+			}
+			return 0, nil
+		}
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be compared with a number in filter, got '%s'", name, value)
+	}
+	return f, nil
+}
+
+func filterFieldNames() []string {
+	names := []string{"tag"}
+	for name := range filterFields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// escapeLike escapes the wildcards of LIKE in a value, so that ~ matches % and _ literally.
+// The LIKE must have ESCAPE '\'.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
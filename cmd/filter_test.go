@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		filter  string
+		where   string
+		args    []any
+		ignored bool
+	}{
+		{
+			filter: `payee ~ "whole foods"`,
+			where:  `COALESCE(payee_name, counter_party) LIKE '%' || ? || '%' ESCAPE '\'`,
+			args:   []any{"whole foods"},
+		},
+		{
+			filter: `payee ~ "50%_off"`,
+			where:  `COALESCE(payee_name, counter_party) LIKE '%' || ? || '%' ESCAPE '\'`,
+			args:   []any{`50\%\_off`},
+		},
+		{
+			filter: `description !~ 'a\b'`,
+			where:  `(description IS NULL OR description NOT LIKE '%' || ? || '%' ESCAPE '\')`,
+			args:   []any{`a\\b`},
+		},
+		{
+			filter: `category != Groceries`,
+			where:  `category_name IS NOT ?`,
+			args:   []any{"Groceries"},
+		},
+		{
+			filter: `amount < -50`,
+			where:  `amount < ?`,
+			args:   []any{-50.0},
+		},
+		{
+			filter: `amount>=10 and amount<=20`,
+			where:  `amount >= ? AND amount <= ?`,
+			args:   []any{10.0, 20.0},
+		},
+		{
+			filter: `category is null or not (kind is not null)`,
+			where:  `category_name IS NULL OR NOT (category_kind IS NOT NULL)`,
+		},
+		{
+			filter: `PAYEE = "Whole Foods" AND Date > 2026-01-01`,
+			where:  `COALESCE(payee_name, counter_party) = ? AND date > ?`,
+			args:   []any{"Whole Foods", "2026-01-01"},
+		},
+		{
+			filter:  `ignored = true`,
+			where:   `ignore_when_summing = ?`,
+			args:    []any{1},
+			ignored: true,
+		},
+		{
+			filter:  `amount < 0 and not ignored = 0`,
+			where:   `amount < ? AND NOT ignore_when_summing = ?`,
+			args:    []any{0.0, 0},
+			ignored: true,
+		},
+		{
+			filter: `tag != vacation`,
+			where:  `transaction_id NOT IN (SELECT transaction_tags.transaction_id FROM transaction_tags JOIN tags ON tags.id = transaction_tags.tag_id WHERE tags.name = ?)`,
+			args:   []any{"vacation"},
+		},
+	}
+	for _, test := range tests {
+		f, err := parseFilter(test.filter)
+		if err != nil {
+			t.Errorf("parseFilter(%q) returned error: %v", test.filter, err)
+			continue
+		}
+		if f.where != test.where {
+			t.Errorf("parseFilter(%q).where = %q, want %q", test.filter, f.where, test.where)
+		}
+		if !reflect.DeepEqual(f.args, test.args) {
+			t.Errorf("parseFilter(%q).args = %#v, want %#v", test.filter, f.args, test.args)
+		}
+		if f.ignored != test.ignored {
+			t.Errorf("parseFilter(%q).ignored = %v, want %v", test.filter, f.ignored, test.ignored)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		filter string
+		err    string
+	}{
+		{`payee ~ "whole foods`, "unterminated quote"},
+		{`payee ~`, "ends unexpectedly"},
+		{`merchant = x`, "unknown field 'merchant'"},
+		{`"payee" = x`, "unknown field 'payee'"},
+		{`amount < fifty`, "amount must be compared with a number"},
+		{`payee like x`, "expected an operator after 'payee'"},
+		{`category is empty`, "expected null after 'category is'"},
+		{`(amount < 0`, "missing ')'"},
+		{`amount < 0 amount > 1`, "unexpected 'amount'"},
+		{`tag ~ vacation`, "tag can only be compared with = or !="},
+	}
+	for _, test := range tests {
+		_, err := parseFilter(test.filter)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("parseFilter(%q) error = %v, want one containing %q", test.filter, err, test.err)
+		}
+	}
+}
+
+func TestTokenizeFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		tokens []filterToken
+	}{
+		{`amount<=-5`, []filterToken{{text: "amount"}, {text: "<="}, {text: "-5"}}},
+		{`payee!~"a b"`, []filterToken{{text: "payee"}, {text: "!~"}, {text: "a b", quoted: true}}},
+		{`(tag='x')`, []filterToken{{text: "("}, {text: "tag"}, {text: "="}, {text: "x", quoted: true}, {text: ")"}}},
+		{`payee = ""`, []filterToken{{text: "payee"}, {text: "="}, {text: "", quoted: true}}},
+	}
+	for _, test := range tests {
+		tokens, err := tokenizeFilter(test.filter)
+		if err != nil {
+			t.Errorf("tokenizeFilter(%q) returned error: %v", test.filter, err)
+			continue
+		}
+		if !reflect.DeepEqual(tokens, test.tokens) {
+			t.Errorf("tokenizeFilter(%q) = %v, want %v", test.filter, tokens, test.tokens)
+		}
+	}
+}
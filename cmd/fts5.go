@@ -0,0 +1,8 @@
+//go:build !sqlite_fts5
+
+package cmd
+
+// trackit searches transactions with SQLite's full-text search (FTS5), which go-sqlite3 only
+// compiles in with the sqlite_fts5 build tag, and its migrations create a full-text index.
+// Without the tag, this undefined name stops the build: go build -tags sqlite_fts5
+var _ = trackit_must_be_built_with_tags_sqlite_fts5
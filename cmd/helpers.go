@@ -10,23 +10,59 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
-	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
 	"github.com/kahunacohen/trackit/internal/models"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
+// sqliteDriver is the sqlite3 driver with a regexp function, so that queries can match Go
+// regular expressions with REGEXP, the way rules do.
+const sqliteDriver = "sqlite3_trackit"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("regexp", sqliteRegexp, true)
+		},
+	})
+}
+
+// sqliteRegexps caches the regular expressions compiled by sqliteRegexp, which is called
+// once per row.
+var sqliteRegexps sync.Map
+
+// sqliteRegexp implements value REGEXP pattern. Like rules, it reports whether the value
+// contains a match of the pattern. NULL values match nothing.
+func sqliteRegexp(pattern string, value any) (bool, error) {
+	s, ok := value.(string)
+	if !ok {
+		return false, nil
+	}
+	re, ok := sqliteRegexps.Load(pattern)
+	if !ok {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return false, fmt.Errorf("invalid regular expression '%s': %w", pattern, err)
+		}
+		re, _ = sqliteRegexps.LoadOrStore(pattern, compiled)
+	}
+	return re.(*regexp.Regexp).MatchString(s), nil
+}
+
 func roundAmount(amount float64) float64 {
 	return math.Round(amount*100) / 100
 }
 
 // getDB gets the sqlite db instance, opening it
-// with the proper DSN mode. It also migrates the
-// schema if new migrations are found.
+// with the proper DSN mode and foreign keys enforced.
+// It also migrates the schema if new migrations are found.
 func getDB(dbPath string) (*sql.DB, error) {
 	_, err := os.Stat(dbPath)
 	dbExists := !os.IsNotExist(err)
@@ -40,7 +76,9 @@ func getDB(dbPath string) (*sql.DB, error) {
 		dsn = fmt.Sprintf("%s?mode=rw&create=true", dbPath)
 	}
 	logF(verbose, "opening database: %s", dsn)
-	db, err := sql.Open("sqlite3", dsn)
+	// Foreign keys are enforced on the app's connections only. Migrations that rebuild
+	// tables would otherwise delete the rows referencing them.
+	db, err := sql.Open(sqliteDriver, dsn+"&_foreign_keys=1")
 	if err != nil {
 		return nil, err
 	}
@@ -68,38 +106,93 @@ func runMigrations(dsn string) error {
 	return nil
 }
 
-func renderTransactionTable(rows []models.TransactionsView, total *float64) error {
-	t := table.NewWriter()
-	t.SetStyle(table.StyleLight)
-	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"ID", "Date", "Payee", "Account", "Category", "Ignore", "Amount"})
+// transactionReport returns the report of transactions, with the totals of those that
+// aren't ignored.
+func transactionReport(rows []models.TransactionsView) report {
+	r := report{columns: []outputColumn{
+		{key: "id", header: "ID"},
+		{key: "date", header: "Date"},
+		{key: "payee", header: "Payee"},
+		{key: "counter_party"},
+		{key: "description"},
+		{header: "Account"},
+		{key: "account"},
+		{key: "category", header: "Category"},
+		{key: "kind"},
+		{key: "ignored", header: "Ignore"},
+		{key: "amount", header: "Amount", align: text.AlignRight},
+	}}
+	var summary amountSummary
 	for _, row := range rows {
-		var category string
-		if row.CategoryName.Valid {
-			category = row.CategoryName.String
-		} else {
-			category = "-"
+		if row.IgnoreWhenSumming == 0 {
+			summary.add(row.CategoryKind.String, row.Amount)
 		}
-		ignoreVal := "No"
-		if row.IgnoreWhenSumming == 1 {
-			ignoreVal = "Yes"
+		r.rows = append(r.rows, []any{row.TransactionID, row.Date, payeeName(row), row.CounterParty,
+			nullValue(row.Description), accountKeyToName(row.AccountName), nullValue(row.AccountName),
+			nullValue(row.CategoryName), nullValue(row.CategoryKind), row.IgnoreWhenSumming == 1, outputAmount(row.Amount)})
+	}
+	r.summary = summary.lines()
+	return r
+}
+
+// renderTransactionTable renders transactions as a table, whatever the --output flag, for
+// commands that show the transactions they change.
+func renderTransactionTable(rows []models.TransactionsView) error {
+	return transactionReport(rows).write(os.Stdout, "table")
+}
+
+// amountSummary totals amounts by the kind of their category. Transfers aren't counted
+// towards the net, and uncategorized amounts count as income or expenses by their sign.
+type amountSummary struct {
+	Income   float64
+	Expenses float64
+	Savings  float64
+}
+
+func (s *amountSummary) add(kind string, amount float64) {
+	switch kind {
+	case "income":
+		s.Income += amount
+	case "expense":
+		s.Expenses += amount
+	case "savings":
+		s.Savings += amount
+	case "transfer":
+	default:
+		if amount > 0 {
+			s.Income += amount
+		} else {
+			s.Expenses += amount
 		}
-		t.AppendRow([]interface{}{row.TransactionID, row.Date, row.CounterParty, accountKeyToName(row.AccountName), category, ignoreVal, fmt.Sprintf("%.2f", row.Amount)})
 	}
-	totalStr := "0.00"
-	if total != nil {
-		totalStr = strconv.FormatFloat(*total, 'f', 2, 64) // 'f' for floating-point format, 2 digits after the decimal
+}
+
+func (s amountSummary) Net() float64 {
+	return s.Income + s.Expenses + s.Savings
+}
+
+type summaryLine struct {
+	label  string
+	amount float64
+}
+
+// lines returns the totals shown in table footers and JSON summaries.
+func (s amountSummary) lines() []summaryLine {
+	return []summaryLine{
+		{"Income", s.Income},
+		{"Expenses", s.Expenses},
+		{"Savings", s.Savings},
+		{"Net", s.Net()},
 	}
+}
 
-	t.AppendFooter(table.Row{"", "", "", "", "", "Total", totalStr})
-	t.SetColumnConfigs([]table.ColumnConfig{
-		{
-			Name:  "Amount",
-			Align: 4,
-		},
-	})
-	t.Render()
-	return nil
+// payeeName returns the canonical name of the transaction's payee, falling back to the
+// raw counter party when no payee alias matched it.
+func payeeName(row models.TransactionsView) string {
+	if row.PayeeName.Valid {
+		return row.PayeeName.String
+	}
+	return row.CounterParty
 }
 
 func validateYearMonthFormat(s string) bool {
@@ -119,7 +119,7 @@ func initAccounts(conf *config.Config, db *sql.DB) error {
 
 func initCategories(ctx context.Context, conf *config.Config, queries *models.Queries) error {
 	for _, category := range maps.Keys(conf.Categories) {
-		if err := queries.CreateCategory(ctx, category); err != nil {
+		if _, err := createCategoryPath(ctx, queries, category); err != nil {
 			return err
 		}
 
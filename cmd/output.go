@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// outputFormats are the formats the --output flag takes.
+var outputFormats = []string{"table", "json", "jsonl", "csv", "tsv", "markdown"}
+
+// outputColumn is a column of a report. Columns without a key are only shown in tables,
+// e.g. a name indented by its depth, and columns without a header are left out of tables,
+// e.g. the raw counter party of a transaction.
+type outputColumn struct {
+	key    string
+	header string
+	align  text.Align
+}
+
+// outputAmount is an amount of money. It's shown with two decimals, and output as a
+// number in JSON.
+type outputAmount float64
+
+func (a outputAmount) String() string {
+	return strconv.FormatFloat(float64(a), 'f', 2, 64)
+}
+
+func (a outputAmount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// report is the output of a command that reads rows, with a value per column in each row.
+// Values are nil for nulls, []string for lists, and reports for rows of their own, e.g. the
+// splits of a transaction. Summary totals, if any, are shown in the table footer, and under
+// their own key in JSON. Vertical tables show each column of a row on a line of its own,
+// for reports of a single row.
+type report struct {
+	title    string
+	columns  []outputColumn
+	rows     [][]any
+	summary  []summaryLine
+	vertical bool
+}
+
+// printReport prints a report to stdout, in the format of the --output flag.
+func printReport(r report) error {
+	format, _ := rootCmd.PersistentFlags().GetString("output")
+	return r.write(os.Stdout, format)
+}
+
+// printPagedReport prints a report like printReport, but through $PAGER (less by default)
+// when stdout is a terminal, unless paging is turned off.
+func printPagedReport(r report, noPager bool) error {
+	format, _ := rootCmd.PersistentFlags().GetString("output")
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		pager = []string{"less"}
+	}
+	stat, err := os.Stdout.Stat()
+	if noPager || err != nil || stat.Mode()&os.ModeCharDevice == 0 {
+		return r.write(os.Stdout, format)
+	}
+	path, err := exec.LookPath(pager[0])
+	if err != nil {
+		return r.write(os.Stdout, format)
+	}
+	var b bytes.Buffer
+	if err := r.write(&b, format); err != nil {
+		return err
+	}
+	cmd := exec.Command(path, pager[1:]...)
+	cmd.Stdin = &b
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		// Quit if the output fits the screen, keep colors and don't clear the screen on exit.
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error running pager %s: %w", pager[0], err)
+	}
+	return nil
+}
+
+func (r report) write(w io.Writer, format string) error {
+	if err := validateOutputFormat(format); err != nil {
+		return err
+	}
+	switch format {
+	case "json":
+		return r.writeJSON(w)
+	case "jsonl":
+		return r.writeJSONLines(w)
+	case "csv":
+		return r.writeCSV(w, ',')
+	case "tsv":
+		return r.writeCSV(w, '\t')
+	}
+	return r.writeTable(w, format)
+}
+
+func (r report) writeTable(w io.Writer, format string) error {
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(w)
+	t.SetTitle(r.title)
+	if r.vertical {
+		if format == "markdown" {
+			// Markdown tables need a header row.
+			t.AppendHeader(table.Row{"Field", "Value"})
+		}
+		for _, values := range r.rows {
+			for i, column := range r.columns {
+				if column.header != "" {
+					t.AppendRow(table.Row{column.header, tableValue(values[i])})
+				}
+			}
+		}
+		renderTable(t, format)
+		return nil
+	}
+	var header table.Row
+	var configs []table.ColumnConfig
+	for _, column := range r.columns {
+		if column.header != "" {
+			header = append(header, column.header)
+			configs = append(configs, table.ColumnConfig{Name: column.header, Align: column.align})
+		}
+	}
+	t.AppendHeader(header)
+	t.SetColumnConfigs(configs)
+	for _, values := range r.rows {
+		var row table.Row
+		for i, column := range r.columns {
+			if column.header != "" {
+				row = append(row, tableValue(values[i]))
+			}
+		}
+		t.AppendRow(row)
+	}
+	for _, line := range r.summary {
+		row := make(table.Row, len(header)-2, len(header))
+		for i := range row {
+			row[i] = ""
+		}
+		t.AppendFooter(append(row, line.label, outputAmount(line.amount)))
+	}
+	renderTable(t, format)
+	return nil
+}
+
+func renderTable(t table.Writer, format string) {
+	if format == "markdown" {
+		t.RenderMarkdown()
+	} else {
+		t.Render()
+	}
+}
+
+func tableValue(value any) any {
+	switch v := value.(type) {
+	case nil:
+		return "-"
+	case bool:
+		if v {
+			return "Yes"
+		}
+		return "No"
+	case []string:
+		if len(v) == 0 {
+			return "-"
+		}
+		return strings.Join(v, "\n")
+	}
+	return value
+}
+
+// jsonRows returns the rows as JSON objects, keyed by the column keys.
+func (r report) jsonRows() []jsonObject {
+	objects := []jsonObject{}
+	for _, values := range r.rows {
+		var object jsonObject
+		for i, column := range r.columns {
+			if column.key == "" {
+				continue
+			}
+			value := values[i]
+			if list, ok := value.([]string); ok && list == nil {
+				value = []string{}
+			}
+			if nested, ok := value.(report); ok {
+				value = nested.jsonRows()
+			}
+			object.keys = append(object.keys, column.key)
+			object.values = append(object.values, value)
+		}
+		objects = append(objects, object)
+	}
+	return objects
+}
+
+func (r report) writeJSON(w io.Writer) error {
+	output := jsonObject{keys: []string{"rows"}, values: []any{r.jsonRows()}}
+	if r.summary != nil {
+		var summary jsonObject
+		for _, line := range r.summary {
+			summary.keys = append(summary.keys, strings.ToLower(line.label))
+			summary.values = append(summary.values, outputAmount(line.amount))
+		}
+		output.keys = append(output.keys, "summary")
+		output.values = append(output.values, summary)
+	}
+	b, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+func (r report) writeJSONLines(w io.Writer) error {
+	for _, object := range r.jsonRows() {
+		b, err := json.Marshal(object)
+		if err != nil {
+			return fmt.Errorf("error encoding JSON: %w", err)
+		}
+		if _, err := fmt.Fprintln(w, string(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r report) writeCSV(w io.Writer, comma rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+	var header []string
+	for _, column := range r.columns {
+		if column.key != "" {
+			header = append(header, column.key)
+		}
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("error writing CSV: %w", err)
+	}
+	for _, values := range r.rows {
+		var record []string
+		for i, column := range r.columns {
+			if column.key != "" {
+				record = append(record, csvValue(values[i]))
+			}
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing CSV: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []string:
+		return strings.Join(v, "; ")
+	case report:
+		// Each row as key=value pairs, e.g. category=Groceries amount=-30.00 memo=
+		var rows []string
+		for _, values := range v.rows {
+			var pairs []string
+			for i, column := range v.columns {
+				if column.key != "" {
+					pairs = append(pairs, column.key+"="+csvValue(values[i]))
+				}
+			}
+			rows = append(rows, strings.Join(pairs, " "))
+		}
+		return strings.Join(rows, "; ")
+	}
+	return fmt.Sprint(value)
+}
+
+// jsonObject is a JSON object that keeps the order of its keys.
+type jsonObject struct {
+	keys   []string
+	values []any
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// nullValue returns the string, or nil if it's null.
+func nullValue(s sql.NullString) any {
+	if !s.Valid {
+		return nil
+	}
+	return s.String
+}
+
+func validateOutputFormat(format string) error {
+	if !slices.Contains(outputFormats, format) {
+		return fmt.Errorf("output '%s' is invalid. Must be one of: %s", format, strings.Join(outputFormats, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var payeeAliasCmd = &cobra.Command{
+	Use:   "alias",
+	Args:  cobra.MinimumNArgs(2),
+	Short: "Adds aliases to a payee. trackit payee alias <name> <regexp> [<regexp>...]",
+	Long: `Adds alias regular expressions to a payee, creating the payee if it doesn't exist. Existing
+transactions with no payee whose counter party matches an alias are linked to the payee. E.g.:
+
+$ trackit payee alias Amazon '^AMAZON MKTPLACE' '^AMZN Mktp' '(?i)^amazon\.com'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		patterns := args[1:]
+		for _, pattern := range patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("invalid regular expression '%s': %w", pattern, err)
+			}
+		}
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		ctx := context.Background()
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("error beginning db transaction: %w", err)
+		}
+		defer tx.Rollback()
+		queries := models.New(tx)
+		if err := queries.CreatePayee(ctx, name); err != nil {
+			return fmt.Errorf("error creating payee %s: %w", name, err)
+		}
+		payeeID, err := queries.ReadPayeeIdByName(ctx, name)
+		if err != nil {
+			return fmt.Errorf("error getting payee ID for %s: %w", name, err)
+		}
+		for _, pattern := range patterns {
+			err := queries.CreatePayeeAlias(ctx, models.CreatePayeeAliasParams{PayeeID: payeeID, Pattern: pattern})
+			if err != nil {
+				return fmt.Errorf("error adding alias '%s' to payee %s: %w", pattern, name, err)
+			}
+		}
+		resolved, err := resolveStoredPayees(ctx, queries)
+		if err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("error committing db transaction: %w", err)
+		}
+		fmt.Printf("linked %d transactions to payees\n", resolved)
+		return nil
+	},
+}
+
+func init() {
+	payeeCmd.AddCommand(payeeAliasCmd)
+}
@@ -0,0 +1,53 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var payeeListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "Lists payees with their aliases",
+	Long:    `Lists payees with their aliases and the number of transactions linked to them. trackit payee list`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		ctx := context.Background()
+		queries := models.New(db)
+		payees, err := queries.ReadAllPayees(ctx)
+		if err != nil {
+			return fmt.Errorf("error reading payees: %w", err)
+		}
+		aliases, err := queries.ReadAllPayeeAliases(ctx)
+		if err != nil {
+			return fmt.Errorf("error reading payee aliases: %w", err)
+		}
+		patterns := make(map[int64][]string)
+		for _, alias := range aliases {
+			patterns[alias.PayeeID] = append(patterns[alias.PayeeID], alias.Pattern)
+		}
+		r := report{columns: []outputColumn{{key: "id", header: "ID"}, {key: "name", header: "Name"}, {key: "aliases", header: "Aliases"}, {key: "transactions", header: "Transactions"}}}
+		for _, payee := range payees {
+			r.rows = append(r.rows, []any{payee.ID, payee.Name, patterns[payee.ID], payee.TransactionCount})
+		}
+		return printReport(r)
+	},
+}
+
+func init() {
+	payeeCmd.AddCommand(payeeListCmd)
+}
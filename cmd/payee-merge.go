@@ -0,0 +1,82 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var payeeMergeCmd = &cobra.Command{
+	Use:   "merge",
+	Args:  cobra.MinimumNArgs(2),
+	Short: "Merges payees into another. trackit payee merge <from> [<from>...] <into>",
+	Long: `Merges payees into another payee. The aliases and transactions of the payees merged from
+are moved to the payee merged into, which is created if it doesn't exist, and the payees merged
+from are deleted. Merging a single payee into a new name renames it. E.g.:
+
+$ trackit payee merge "Amazon Marketplace" "Amazon.com" Amazon`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		from := args[:len(args)-1]
+		into := args[len(args)-1]
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		ctx := context.Background()
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("error beginning db transaction: %w", err)
+		}
+		defer tx.Rollback()
+		queries := models.New(tx)
+		var fromIDs []int64
+		for _, name := range from {
+			if name == into {
+				return fmt.Errorf("can't merge payee %s into itself", name)
+			}
+			id, err := queries.ReadPayeeIdByName(ctx, name)
+			if err != nil {
+				return fmt.Errorf("error getting payee ID for %s. Do trackit payee list to see existing payees: %w", name, err)
+			}
+			fromIDs = append(fromIDs, id)
+		}
+		if err := queries.CreatePayee(ctx, into); err != nil {
+			return fmt.Errorf("error creating payee %s: %w", into, err)
+		}
+		intoID, err := queries.ReadPayeeIdByName(ctx, into)
+		if err != nil {
+			return fmt.Errorf("error getting payee ID for %s: %w", into, err)
+		}
+		for i, fromID := range fromIDs {
+			err := queries.MovePayeeAliases(ctx, models.MovePayeeAliasesParams{IntoID: intoID, FromID: fromID})
+			if err != nil {
+				return fmt.Errorf("error moving aliases of payee %s: %w", from[i], err)
+			}
+			err = queries.MovePayeeTransactions(ctx, models.MovePayeeTransactionsParams{
+				IntoID: sql.NullInt64{Valid: true, Int64: intoID},
+				FromID: sql.NullInt64{Valid: true, Int64: fromID}})
+			if err != nil {
+				return fmt.Errorf("error moving transactions of payee %s: %w", from[i], err)
+			}
+			if err := queries.DeletePayee(ctx, fromID); err != nil {
+				return fmt.Errorf("error deleting payee %s: %w", from[i], err)
+			}
+		}
+		return tx.Commit()
+	},
+}
+
+func init() {
+	payeeCmd.AddCommand(payeeMergeCmd)
+}
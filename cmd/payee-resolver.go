@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+
+	"github.com/kahunacohen/trackit/internal/models"
+)
+
+type compiledPayeeAlias struct {
+	payeeID int64
+	re      *regexp.Regexp
+}
+
+// payeeResolver links raw counter parties to payees by their alias regular expressions,
+// which are compiled once, when the resolver is created. Aliases are tried in the order
+// they were added.
+type payeeResolver struct {
+	aliases []compiledPayeeAlias
+}
+
+func newPayeeResolver(ctx context.Context, queries *models.Queries) (*payeeResolver, error) {
+	rows, err := queries.ReadAllPayeeAliases(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading payee aliases: %w", err)
+	}
+	resolver := &payeeResolver{}
+	for _, row := range rows {
+		re, err := regexp.Compile(row.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("alias '%s' of payee %s is an invalid regular expression: %w", row.Pattern, row.PayeeName, err)
+		}
+		resolver.aliases = append(resolver.aliases, compiledPayeeAlias{payeeID: row.PayeeID, re: re})
+	}
+	return resolver, nil
+}
+
+// resolve returns the ID of the payee of the counter party, or null if no alias matches it.
+func (r *payeeResolver) resolve(counterParty string) sql.NullInt64 {
+	for _, alias := range r.aliases {
+		if alias.re.MatchString(counterParty) {
+			return sql.NullInt64{Valid: true, Int64: alias.payeeID}
+		}
+	}
+	return sql.NullInt64{}
+}
+
+// resolveStoredPayees links the stored transactions that have no payee yet to the payee
+// of their counter party, and returns how many it linked.
+func resolveStoredPayees(ctx context.Context, queries *models.Queries) (int, error) {
+	resolver, err := newPayeeResolver(ctx, queries)
+	if err != nil {
+		return 0, err
+	}
+	transactions, err := queries.ReadTransactionsWithoutPayee(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error reading transactions without a payee: %w", err)
+	}
+	resolved := 0
+	for _, transaction := range transactions {
+		payeeID := resolver.resolve(transaction.CounterParty)
+		if !payeeID.Valid {
+			continue
+		}
+		err := queries.UpdateTransactionPayee(ctx, models.UpdateTransactionPayeeParams{PayeeID: payeeID, ID: transaction.ID})
+		if err != nil {
+			return 0, fmt.Errorf("error setting payee of transaction %d: %w", transaction.ID, err)
+		}
+		resolved++
+	}
+	return resolved, nil
+}
@@ -0,0 +1,24 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var payeeCmd = &cobra.Command{
+	Use:   "payee",
+	Short: "Manages payees",
+	Long: `Manages payees. A payee is the canonical name of a merchant or other counter party that
+shows up under several names in your bank statements. Each payee has alias regular expressions,
+and transactions whose counter party matches one of them are linked to the payee when they are
+imported or created. Listings show the payee name, while the raw counter party is kept as is.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(payeeCmd)
+}
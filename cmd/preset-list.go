@@ -0,0 +1,33 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kahunacohen/trackit/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var presetListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "Lists the built-in bank CSV format presets",
+	Long:    `Lists the built-in bank CSV format presets. trackit preset list`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		presets, err := config.Presets()
+		if err != nil {
+			return fmt.Errorf("error reading presets: %w", err)
+		}
+		r := report{columns: []outputColumn{{key: "name", header: "Name"}, {key: "currency", header: "Currency"}, {key: "date_layout", header: "Date Layout"}, {key: "description", header: "Description"}}}
+		for _, preset := range presets {
+			r.rows = append(r.rows, []any{preset.Name, preset.Account.Currency, preset.Account.DateLayout, preset.Description})
+		}
+		return printReport(r)
+	},
+}
+
+func init() {
+	presetCmd.AddCommand(presetListCmd)
+}
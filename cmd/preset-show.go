@@ -0,0 +1,31 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kahunacohen/trackit/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var presetShowCmd = &cobra.Command{
+	Use:   "show",
+	Args:  cobra.ExactArgs(1),
+	Short: "Shows a built-in preset. trackit preset show <name>",
+	Long: `Shows the YAML of a built-in preset, as it would appear in an account block of
+trackit.yaml. trackit preset show <name>`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		preset, err := config.ReadPreset(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Print(preset.Source)
+		return nil
+	},
+}
+
+func init() {
+	presetCmd.AddCommand(presetShowCmd)
+}
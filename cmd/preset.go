@@ -0,0 +1,27 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var presetCmd = &cobra.Command{
+	Use:   "preset",
+	Short: "Inspects the built-in bank CSV format presets",
+	Long: `Inspects the built-in bank CSV format presets. An account in trackit.yaml can reference a preset
+instead of listing its headers, and override any of the preset's fields. E.g.:
+
+accounts:
+  chase_sapphire:
+    preset: chase_credit
+    debit_as_positive: true`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(presetCmd)
+}
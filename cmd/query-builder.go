@@ -0,0 +1,409 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/kahunacohen/trackit/internal/config"
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// transactionFilterFlags are the flags added by addTransactionFilterFlags.
+var transactionFilterFlags = []string{"date", "from", "to", "account", "category", "uncategorized", "ignored",
+	"not-ignored", "min", "max", "sign", "tag", "where"}
+
+// transactionSorts are the columns of transactions_view the --sort flag sorts by.
+var transactionSorts = map[string]string{
+	"date":     `"date"`,
+	"amount":   "amount",
+	"payee":    "COALESCE(payee_name, counter_party) COLLATE NOCASE",
+	"category": "category_name COLLATE NOCASE",
+	"account":  "account_name",
+}
+
+// transactionQuery selects transactions from transactions_view by conditions, which are
+// all met. Conditions on the category are kept apart, so that aggregations can apply them
+// to the splits of split transactions instead of the transactions. The transactions are
+// sorted by orderBy, then newest first, and paged by limit and offset. match is the
+// full-text query of trackit transaction search, which results can be sorted by relevance to.
+// ignored is whether the conditions pick transactions by whether they're ignored, in which
+// case aggregations don't leave the ignored ones out.
+type transactionQuery struct {
+	conditions         []string
+	args               []any
+	categoryConditions []string
+	categoryArgs       []any
+	orderBy            string
+	orderArgs          []any
+	limit              int
+	offset             int
+	match              string
+	ignored            bool
+}
+
+func (q *transactionQuery) where(condition string, args ...any) {
+	q.conditions = append(q.conditions, condition)
+	q.args = append(q.args, args...)
+}
+
+// whereCategory adds a condition on category_name, a column of both transactions_view and
+// transaction_categories_view.
+func (q *transactionQuery) whereCategory(condition string, args ...any) {
+	q.categoryConditions = append(q.categoryConditions, condition)
+	q.categoryArgs = append(q.categoryArgs, args...)
+}
+
+// whereMatch adds the condition that transactions match a full-text query.
+func (q *transactionQuery) whereMatch(match string) {
+	q.where("transaction_id IN (SELECT rowid FROM transactions_fts WHERE transactions_fts MATCH ?)", match)
+	q.match = match
+}
+
+// whereRule adds the conditions of a rule, which hold for the same transactions as
+// compiledRule.matches.
+func (q *transactionQuery) whereRule(rule models.Rule) {
+	if rule.CounterParty.Valid {
+		q.where("counter_party REGEXP ?", rule.CounterParty.String)
+	}
+	if rule.Description.Valid {
+		q.where(`COALESCE("description", '') REGEXP ?`, rule.Description.String)
+	}
+	if rule.AccountID.Valid {
+		q.where("account_id = ?", rule.AccountID.Int64)
+	}
+	if rule.MinAmount.Valid {
+		q.where("ABS(amount) >= ?", rule.MinAmount.Float64)
+	}
+	if rule.MaxAmount.Valid {
+		q.where("ABS(amount) <= ?", rule.MaxAmount.Float64)
+	}
+	if rule.Sign.Valid {
+		if rule.Sign.String == "positive" {
+			q.where("amount > 0")
+		} else {
+			q.where("amount < 0")
+		}
+	}
+	if rule.FromDate.Valid {
+		q.where(`"date" >= ?`, rule.FromDate.String)
+	}
+	if rule.ToDate.Valid {
+		q.where(`"date" <= ?`, rule.ToDate.String)
+	}
+}
+
+// whereIn adds the condition that column is one of values.
+func (q *transactionQuery) whereIn(column string, values []any) {
+	q.where(inCondition(column, len(values)), values...)
+}
+
+func inCondition(column string, n int) string {
+	return fmt.Sprintf("%s IN (%s)", column, strings.TrimSuffix(strings.Repeat("?, ", n), ", "))
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" WHERE (%s)", strings.Join(conditions, ") AND ("))
+}
+
+// paged reports whether the query only selects a page of the transactions.
+func (q *transactionQuery) paged() bool {
+	return q.limit > 0 || q.offset > 0
+}
+
+// transactionsSQL returns the query of the transactions, and its arguments.
+func (q *transactionQuery) transactionsSQL() (string, []any) {
+	conditions := append(slices.Clone(q.conditions), q.categoryConditions...)
+	args := append(slices.Clone(q.args), q.categoryArgs...)
+	orderBy := `"date" DESC, transaction_id DESC`
+	if q.orderBy != "" {
+		orderBy = q.orderBy + ", " + orderBy
+		args = append(args, q.orderArgs...)
+	}
+	query := `SELECT account_id, account_name, transaction_id, date, counter_party, amount, ignore_when_summing,
+    description, category_name, categorized_manually, payee_id, payee_name, category_kind
+FROM transactions_view` + whereClause(conditions) + " ORDER BY " + orderBy
+	if q.paged() {
+		// SQLite only takes an offset after a limit, where -1 is no limit.
+		limit := q.limit
+		if limit == 0 {
+			limit = -1
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, q.offset)
+	}
+	return query, args
+}
+
+// summarySQL returns the query of the totals of the transactions by the kind of their
+// category and sign, ignoring the page, and its arguments.
+func (q *transactionQuery) summarySQL() (string, []any) {
+	conditions := append([]string{"ignore_when_summing = 0"}, q.conditions...)
+	conditions = append(conditions, q.categoryConditions...)
+	args := append(slices.Clone(q.args), q.categoryArgs...)
+	return `SELECT COALESCE(category_kind, ''), SUM(amount) FROM transactions_view` + whereClause(conditions) +
+		" GROUP BY category_kind, amount > 0", args
+}
+
+// categoriesSQL returns the query of the amounts counted per category, the splits of split
+// transactions instead of the transactions, that aren't ignored, unless the conditions pick
+// them by whether they are, and its arguments.
+func (q *transactionQuery) categoriesSQL() (string, []any) {
+	var conditions []string
+	if !q.ignored {
+		conditions = append(conditions, "ignore_when_summing = 0")
+	}
+	var args []any
+	if len(q.conditions) > 0 {
+		conditions = append(conditions, "transaction_id IN (SELECT transaction_id FROM transactions_view"+whereClause(q.conditions)+")")
+		args = append(args, q.args...)
+	}
+	conditions = append(conditions, q.categoryConditions...)
+	args = append(args, q.categoryArgs...)
+	return `SELECT account_name, transaction_id, date, amount, ignore_when_summing, category_name
+FROM transaction_categories_view` + whereClause(conditions) + " ORDER BY date, transaction_id", args
+}
+
+// readTransactions reads the transactions the query selects.
+func readTransactions(ctx context.Context, db models.DBTX, q *transactionQuery) ([]models.TransactionsView, error) {
+	query, args := q.transactionsSQL()
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error reading transactions: %w", err)
+	}
+	defer rows.Close()
+	var transactions []models.TransactionsView
+	for rows.Next() {
+		var t models.TransactionsView
+		err := rows.Scan(&t.AccountID, &t.AccountName, &t.TransactionID, &t.Date, &t.CounterParty, &t.Amount,
+			&t.IgnoreWhenSumming, &t.Description, &t.CategoryName, &t.CategorizedManually, &t.PayeeID,
+			&t.PayeeName, &t.CategoryKind)
+		if err != nil {
+			return nil, fmt.Errorf("error reading transactions: %w", err)
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, rows.Err()
+}
+
+// readTransactionSummary totals all the transactions the query selects, not just those of
+// the page, like the table footer of the transactions.
+func readTransactionSummary(ctx context.Context, db models.DBTX, q *transactionQuery) (amountSummary, error) {
+	var summary amountSummary
+	query, args := q.summarySQL()
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return summary, fmt.Errorf("error totaling transactions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var kind string
+		var amount float64
+		if err := rows.Scan(&kind, &amount); err != nil {
+			return summary, fmt.Errorf("error totaling transactions: %w", err)
+		}
+		summary.add(kind, amount)
+	}
+	return summary, rows.Err()
+}
+
+// readTransactionCategories reads the amounts counted per category of the transactions the
+// query selects, the splits of split transactions instead of the transactions, like
+// categoriesSQL.
+func readTransactionCategories(ctx context.Context, db models.DBTX, q *transactionQuery) ([]models.TransactionCategoriesView, error) {
+	query, args := q.categoriesSQL()
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error reading transactions: %w", err)
+	}
+	defer rows.Close()
+	var categories []models.TransactionCategoriesView
+	for rows.Next() {
+		var c models.TransactionCategoriesView
+		err := rows.Scan(&c.AccountName, &c.TransactionID, &c.Date, &c.Amount, &c.IgnoreWhenSumming, &c.CategoryName)
+		if err != nil {
+			return nil, fmt.Errorf("error reading transactions: %w", err)
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// addTransactionFilterFlags adds the flags that select transactions, shared by the commands
+// that read them.
+func addTransactionFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("date", "d", "", "Only transactions in this month, in YYYY-MM format")
+	cmd.Flags().String("from", "", "Only transactions on or after this date, in YYYY-MM-DD format")
+	cmd.Flags().String("to", "", "Only transactions on or before this date, in YYYY-MM-DD format")
+	cmd.Flags().StringSliceP("account", "a", nil, "Only transactions of this account key from trackit.yaml. Pass multiple --account flags for any of the accounts")
+	cmd.Flags().StringSliceP("category", "c", nil, "Only transactions in this category, or its child categories. Pass multiple --category flags for any of the categories")
+	cmd.Flags().BoolP("uncategorized", "u", false, "Only un-categorized transactions")
+	cmd.Flags().Bool("ignored", false, "Only transactions ignored when summing")
+	cmd.Flags().Bool("not-ignored", false, "Only transactions not ignored when summing")
+	cmd.Flags().String("min", "", "Only transactions with at least this absolute amount")
+	cmd.Flags().String("max", "", "Only transactions with at most this absolute amount")
+	cmd.Flags().String("sign", "", "Only positive (deposits) or negative (withdrawls) transactions")
+	cmd.Flags().StringSliceP("tag", "t", nil, "Only transactions with this tag. Pass multiple --tag flags for transactions with all the tags")
+	cmd.Flags().StringP("where", "w", "", "Only transactions matching a filter, e.g. 'payee ~ shufersal and amount < -100'. See trackit transaction list -h")
+}
+
+// addTransactionListingFlags adds the flags that sort and page listed transactions.
+func addTransactionListingFlags(cmd *cobra.Command, sort string) {
+	cmd.Flags().String("sort", sort, "Sort by date, amount, payee, category or account, or relevance when searching")
+	cmd.Flags().Bool("asc", false, "Sort ascending. The default for all but date")
+	cmd.Flags().Bool("desc", false, "Sort descending. The default for date")
+	cmd.Flags().Int("limit", 0, "Only list this many transactions")
+	cmd.Flags().Int("offset", 0, "Skip this many transactions before listing")
+	cmd.Flags().Bool("no-pager", false, "Don't page the output with $PAGER when it's a terminal")
+}
+
+// sortTransactionQuery sorts and pages a query by the listing flags.
+func sortTransactionQuery(q *transactionQuery, flags *pflag.FlagSet) error {
+	sort, _ := flags.GetString("sort")
+	column, ok := transactionSorts[sort]
+	if sort == "relevance" && q.match != "" {
+		// bm25 ranks, lowest first for the most relevant.
+		column = "(SELECT rank FROM transactions_fts WHERE transactions_fts MATCH ? AND rowid = transaction_id)"
+		q.orderArgs = []any{q.match}
+	} else if !ok {
+		return fmt.Errorf("sort '%s' is invalid. Must be one of: date, amount, payee, category, account, or relevance when searching", sort)
+	}
+	asc, _ := flags.GetBool("asc")
+	desc, _ := flags.GetBool("desc")
+	if asc && desc {
+		return errors.New("--asc and --desc can't be passed together")
+	}
+	if sort == "date" && asc {
+		q.orderBy = `"date", transaction_id`
+	} else if sort != "date" {
+		if desc {
+			column += " DESC"
+		}
+		q.orderBy = column
+	}
+	q.limit, _ = flags.GetInt("limit")
+	q.offset, _ = flags.GetInt("offset")
+	if q.limit < 0 || q.offset < 0 {
+		return errors.New("--limit and --offset can't be negative")
+	}
+	return nil
+}
+
+// hasTransactionFilters reports whether any of the filter flags were passed.
+func hasTransactionFilters(flags *pflag.FlagSet) bool {
+	return slices.ContainsFunc(transactionFilterFlags, flags.Changed)
+}
+
+// transactionQueryFromFlags builds a query from the filter flags. Accounts are checked
+// against the config file at configPath, if there is one.
+func transactionQueryFromFlags(ctx context.Context, queries *models.Queries, flags *pflag.FlagSet, configPath string) (*transactionQuery, error) {
+	q := &transactionQuery{}
+	if date, _ := flags.GetString("date"); date != "" {
+		if !validateYearMonthFormat(date) {
+			return nil, errors.New("date must be in YYYY-MM format")
+		}
+		q.where(`strftime('%Y-%m', "date") = ?`, date)
+	}
+	for _, bound := range []struct{ flag, operator string }{{"from", ">="}, {"to", "<="}} {
+		date, _ := flags.GetString(bound.flag)
+		if date == "" {
+			continue
+		}
+		if !validateDateWithDayFormat(date) {
+			return nil, fmt.Errorf("--%s must be in YYYY-MM-DD format", bound.flag)
+		}
+		q.where(fmt.Sprintf(`"date" %s ?`, bound.operator), date)
+	}
+	if accounts, _ := flags.GetStringSlice("account"); len(accounts) > 0 {
+		conf, err := config.ParseConfig(configPath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("error parsing config: %w", err)
+		}
+		var values []any
+		for _, account := range accounts {
+			if conf != nil {
+				if _, ok := conf.Accounts[account]; !ok {
+					return nil, fmt.Errorf("invalid account specified: %s. Check your config for valid account keys", account)
+				}
+			}
+			values = append(values, account)
+		}
+		q.whereIn("account_name", values)
+	}
+	uncategorized, _ := flags.GetBool("uncategorized")
+	if categories, _ := flags.GetStringSlice("category"); len(categories) > 0 {
+		if uncategorized {
+			return nil, errors.New("--category and --uncategorized can't be passed together")
+		}
+		tree, err := readCategoryTree(ctx, queries)
+		if err != nil {
+			return nil, err
+		}
+		var values []any
+		for _, category := range categories {
+			id, err := tree.categoryId(category)
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range tree.descendants(id) {
+				values = append(values, name)
+			}
+		}
+		q.whereCategory(inCondition("category_name", len(values)), values...)
+	} else if uncategorized {
+		q.whereCategory("category_name IS NULL")
+	}
+	ignored, _ := flags.GetBool("ignored")
+	notIgnored, _ := flags.GetBool("not-ignored")
+	if ignored && notIgnored {
+		return nil, errors.New("--ignored and --not-ignored can't be passed together")
+	} else if ignored {
+		q.where("ignore_when_summing = 1")
+		q.ignored = true
+	} else if notIgnored {
+		q.where("ignore_when_summing = 0")
+		q.ignored = true
+	}
+	for _, bound := range []struct{ flag, operator string }{{"min", ">="}, {"max", "<="}} {
+		value, _ := flags.GetString(bound.flag)
+		if value == "" {
+			continue
+		}
+		amount, err := filterNumber(bound.flag, value)
+		if err != nil {
+			return nil, fmt.Errorf("--%s must be a number", bound.flag)
+		}
+		q.where(fmt.Sprintf("ABS(amount) %s ?", bound.operator), amount)
+	}
+	switch sign, _ := flags.GetString("sign"); sign {
+	case "":
+	case "positive":
+		q.where("amount > 0")
+	case "negative":
+		q.where("amount < 0")
+	default:
+		return nil, fmt.Errorf("sign '%s' is invalid. Must be positive or negative", sign)
+	}
+	tags, _ := flags.GetStringSlice("tag")
+	for _, tag := range splitTags(strings.Join(tags, ",")) {
+		q.where(`transaction_id IN (SELECT transaction_tags.transaction_id FROM transaction_tags
+    JOIN tags ON tags.id = transaction_tags.tag_id WHERE tags.name = ?)`, tag)
+	}
+	if where, _ := flags.GetString("where"); where != "" {
+		f, err := parseFilter(where)
+		if err != nil {
+			return nil, err
+		}
+		q.where(f.where, f.args...)
+		q.ignored = q.ignored || f.ignored
+	}
+	return q, nil
+}
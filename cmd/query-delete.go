@@ -0,0 +1,46 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var queryDeleteCmd = &cobra.Command{
+	Use:     "delete",
+	Aliases: []string{"rm"},
+	Args:    cobra.MinimumNArgs(1),
+	Short:   "Deletes saved queries. trackit query delete <name> [<name>...]",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		queries := models.New(db)
+		for _, name := range args {
+			deleted, err := queries.DeleteSavedQuery(context.Background(), name)
+			if err != nil {
+				return fmt.Errorf("error deleting saved query %s: %w", name, err)
+			}
+			if deleted == 0 {
+				return fmt.Errorf("no saved query named %s", name)
+			}
+			fmt.Printf("deleted saved query %s\n", name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	queryCmd.AddCommand(queryDeleteCmd)
+}
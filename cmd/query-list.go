@@ -0,0 +1,73 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var queryListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "Lists saved queries",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		queries, err := models.New(db).ReadAllSavedQueries(context.Background())
+		if err != nil {
+			return fmt.Errorf("error reading saved queries: %w", err)
+		}
+		r := report{columns: []outputColumn{
+			{key: "name", header: "Name"},
+			{key: "command", header: "Command"},
+			{key: "parameters", header: "Parameters"},
+		}}
+		for _, query := range queries {
+			var queryArgs []string
+			if err := json.Unmarshal([]byte(query.Args), &queryArgs); err != nil {
+				return fmt.Errorf("error decoding the arguments of saved query %s: %w", query.Name, err)
+			}
+			command := []string{"trackit", "transaction", query.Command}
+			for _, arg := range queryArgs {
+				command = append(command, shellQuote(arg))
+			}
+			var params []string
+			for _, arg := range queryArgs {
+				for _, match := range queryParamPattern.FindAllStringSubmatch(arg, -1) {
+					if !slices.Contains(params, match[1]) {
+						params = append(params, match[1])
+					}
+				}
+			}
+			r.rows = append(r.rows, []any{query.Name, strings.Join(command, " "), params})
+		}
+		return printReport(r)
+	},
+}
+
+func init() {
+	queryCmd.AddCommand(queryListCmd)
+}
+
+// shellQuote quotes an argument, if needed, so it can be pasted into a shell.
+func shellQuote(arg string) string {
+	if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=.,:/@%+{}") == "" {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
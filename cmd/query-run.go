@@ -0,0 +1,150 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var queryRunCmd = &cobra.Command{
+	Use:   "run",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Runs a saved query. trackit query run <name> [<parameter>=<value>...] [-- <args>...]",
+	Long: `Runs a saved query, filling in its {{parameters}}. Give parameters values as
+<parameter>=<value>, which also overrides the built-in ones, and pass more arguments for the command
+after --. E.g.:
+
+$ trackit query run dining
+$ trackit query run monthly month=2026-03
+$ trackit query run dining -- --sort amount --limit 10
+
+Global flags, like --output, can be passed as usual.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		params := args[1:]
+		var extra []string
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			if dash < 1 {
+				return errors.New("pass the name of the query before --")
+			}
+			params, extra = args[1:dash], args[dash:]
+		}
+		now := time.Now()
+		values := builtinQueryParams(now)
+		for _, param := range params {
+			key, value, found := strings.Cut(param, "=")
+			if !found {
+				return fmt.Errorf("parameter '%s' is invalid. Must be <parameter>=<value>", param)
+			}
+			values[key] = value
+		}
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		query, err := models.New(db).ReadSavedQueryByName(context.Background(), name)
+		db.Close()
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("no saved query named %s. Do trackit query list to see saved queries", name)
+		} else if err != nil {
+			return fmt.Errorf("error reading saved query %s: %w", name, err)
+		}
+		var queryArgs []string
+		if err := json.Unmarshal([]byte(query.Args), &queryArgs); err != nil {
+			return fmt.Errorf("error decoding the arguments of saved query %s: %w", name, err)
+		}
+		queryArgs, err = expandQueryParams(queryArgs, values, now)
+		if err != nil {
+			return err
+		}
+		target, err := savedQueryCommand(query.Command)
+		if err != nil {
+			return err
+		}
+		if err := target.ParseFlags(append(queryArgs, extra...)); err != nil {
+			return fmt.Errorf("invalid arguments for trackit transaction %s: %w", query.Command, err)
+		}
+		positional := target.Flags().Args()
+		if err := target.ValidateArgs(positional); err != nil {
+			return err
+		}
+		return target.RunE(target, positional)
+	},
+}
+
+func init() {
+	queryCmd.AddCommand(queryRunCmd)
+}
+
+// savedQueryCommand returns the command a saved query runs.
+func savedQueryCommand(command string) (*cobra.Command, error) {
+	switch command {
+	case "list":
+		return transactionListCmd, nil
+	case "search":
+		return transactionSearchCmd, nil
+	case "aggregate":
+		return transactionAggregateCmd, nil
+	}
+	return nil, fmt.Errorf("command '%s' can't be saved. Must be list, search or aggregate", command)
+}
+
+var queryParamPattern = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+var monthsAgoPattern = regexp.MustCompile(`^(\d+)_months_ago$`)
+
+// builtinQueryParams returns the values of the built-in parameters as of now.
+func builtinQueryParams(now time.Time) map[string]string {
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return map[string]string{
+		"today":      now.Format("2006-01-02"),
+		"month":      month.Format("2006-01"),
+		"last_month": month.AddDate(0, -1, 0).Format("2006-01"),
+		"year":       month.Format("2006"),
+	}
+}
+
+// expandQueryParams fills in the parameters of a saved query's arguments. {{N_months_ago}}
+// parameters without a value are worked out from now.
+func expandQueryParams(args []string, values map[string]string, now time.Time) ([]string, error) {
+	var missing []string
+	expanded := make([]string, len(args))
+	for i, arg := range args {
+		expanded[i] = queryParamPattern.ReplaceAllStringFunc(arg, func(match string) string {
+			name := queryParamPattern.FindStringSubmatch(match)[1]
+			if value, ok := values[name]; ok {
+				return value
+			}
+			if m := monthsAgoPattern.FindStringSubmatch(name); m != nil {
+				months, _ := strconv.Atoi(m[1])
+				first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+				return first.AddDate(0, -months, 0).Format("2006-01-02")
+			}
+			if !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+			return match
+		})
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("the query needs a value for %s. Pass it as <parameter>=<value>", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
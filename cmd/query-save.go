@@ -0,0 +1,94 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var querySaveCmd = &cobra.Command{
+	Use:   "save",
+	Args:  cobra.MinimumNArgs(2),
+	Short: "Saves a query. trackit query save <name> -- <list|search|aggregate> [<args>...]",
+	Long: `Saves a trackit transaction list, search or aggregate command, with its arguments, by name.
+Pass the command after --, so its flags aren't taken as flags of trackit query save. Saving a query
+under an existing name replaces it. E.g.:
+
+$ trackit query save dining -- list --category "Dining Out" --from {{3_months_ago}} --where 'account != business'
+$ trackit query save monthly -- aggregate --date {{month}}
+
+Arguments can have {{parameters}}, filled in by trackit query run. These are built in:
+
+{{today}}         today's date, in YYYY-MM-DD format
+{{month}}         this month, in YYYY-MM format
+{{last_month}}    last month, in YYYY-MM format
+{{year}}          this year, in YYYY format
+{{N_months_ago}}  the first day of the month N months ago, e.g. {{3_months_ago}}
+
+Any other parameter, e.g. {{account}}, must be given a value when the query is run.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, command, commandArgs := args[0], args[1], args[2:]
+		if err := validateSavedQuery(command, commandArgs); err != nil {
+			return err
+		}
+		encoded, err := json.Marshal(commandArgs)
+		if err != nil {
+			return fmt.Errorf("error encoding query arguments: %w", err)
+		}
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		ctx := context.Background()
+		queries := models.New(db)
+		_, err = queries.ReadSavedQueryByName(ctx, name)
+		replaced := err == nil
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("error reading saved query %s: %w", name, err)
+		}
+		err = queries.CreateSavedQuery(ctx, models.CreateSavedQueryParams{Name: name, Command: command, Args: string(encoded)})
+		if err != nil {
+			return fmt.Errorf("error saving query: %w", err)
+		}
+		if replaced {
+			fmt.Printf("replaced saved query %s\n", name)
+		} else {
+			fmt.Printf("saved query %s. Run it with trackit query run %s\n", name, name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	queryCmd.AddCommand(querySaveCmd)
+}
+
+// validateSavedQuery checks that a query's command exists, and that its flags parse, with
+// a stand-in value for each parameter.
+func validateSavedQuery(command string, args []string) error {
+	target, err := savedQueryCommand(command)
+	if err != nil {
+		return err
+	}
+	parsed := make([]string, len(args))
+	for i, arg := range args {
+		parsed[i] = queryParamPattern.ReplaceAllString(arg, "0")
+	}
+	if err := target.ParseFlags(parsed); err != nil {
+		return fmt.Errorf("invalid arguments for trackit transaction %s: %w", command, err)
+	}
+	return target.ValidateArgs(target.Flags().Args())
+}
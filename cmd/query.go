@@ -0,0 +1,23 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Manages saved queries",
+	Long: `Manages saved queries. A saved query is a trackit transaction list, search or aggregate
+command, with its filters, saved by name, so that it can be run again without re-typing them.
+Saved queries can have {{parameters}}, which are filled in when they are run.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+}
@@ -7,9 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 
-	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/kahunacohen/trackit/internal/models"
 	"github.com/spf13/cobra"
 )
@@ -49,15 +47,11 @@ var rateListCmd = &cobra.Command{
 				rates = append(rates, models.ReadAllRatesRow(r))
 			}
 		}
-		t := table.NewWriter()
-		t.SetStyle(table.StyleLight)
-		t.SetOutputMirror(os.Stdout)
-		t.AppendHeader(table.Row{"ID", "Month", "From", "Rate"})
+		r := report{columns: []outputColumn{{key: "id", header: "ID"}, {key: "month", header: "Month"}, {key: "from", header: "From"}, {key: "rate", header: "Rate"}}}
 		for _, rate := range rates {
-			t.AppendRow([]interface{}{rate.ID, rate.Month, rate.FromCurrencySymbol, rate.Rate})
+			r.rows = append(r.rows, []any{rate.ID, rate.Month, rate.FromCurrencySymbol, rate.Rate})
 		}
-		t.Render()
-		return nil
+		return printReport(r)
 
 	},
 }
@@ -19,6 +19,10 @@ For example:
 
 # initalizes your app by pointing by default to ~/.trackit.yaml
 $ trackit init `,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("output")
+		return validateOutputFormat(format)
+	},
 }
 
 func Execute() {
@@ -30,6 +34,7 @@ func Execute() {
 
 func init() {
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose mode")
+	rootCmd.PersistentFlags().StringP("output", "o", "table", "Output format of commands that list rows: table, json, jsonl, csv, tsv or markdown")
 }
 
 func logLn(msg string, verbose bool) {
@@ -0,0 +1,207 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var ruleCreateCmd = &cobra.Command{
+	Use:     "create",
+	Aliases: []string{"add"},
+	Short:   "Creates a categorization rule",
+	Long: `Creates a categorization rule from at least one condition and one action. E.g.:
+
+$ trackit rule add --payee '(?i)whole foods|shufersal' --category Groceries
+$ trackit rule add --account visa --sign positive --description 'PAYMENT' --ignore
+$ trackit rule add --payee UBER --from 2026-07-01 --to 2026-07-14 --tag vacation-2026
+
+The new rule gets the lowest priority unless --priority is passed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		ctx := context.Background()
+		queries := models.New(db)
+		rules, err := queries.ReadAllRules(ctx)
+		if err != nil {
+			return fmt.Errorf("error reading rules: %w", err)
+		}
+		var rule models.Rule
+		rule.Priority = 1
+		if len(rules) > 0 {
+			rule.Priority = rules[len(rules)-1].Priority + 1
+		}
+		if err := ruleFromFlags(ctx, queries, cmd.Flags(), &rule); err != nil {
+			return err
+		}
+		_, err = queries.CreateRule(ctx, models.CreateRuleParams{
+			Priority:          rule.Priority,
+			CounterParty:      rule.CounterParty,
+			Description:       rule.Description,
+			AccountID:         rule.AccountID,
+			MinAmount:         rule.MinAmount,
+			MaxAmount:         rule.MaxAmount,
+			Sign:              rule.Sign,
+			FromDate:          rule.FromDate,
+			ToDate:            rule.ToDate,
+			CategoryID:        rule.CategoryID,
+			IgnoreWhenSumming: rule.IgnoreWhenSumming,
+			Tags:              rule.Tags,
+		})
+		if err != nil {
+			return fmt.Errorf("error creating rule: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	addRuleFlags(ruleCreateCmd)
+	ruleCmd.AddCommand(ruleCreateCmd)
+}
+
+// addRuleFlags adds the flags for a rule's conditions and actions, shared by rule create
+// and rule update.
+func addRuleFlags(cmd *cobra.Command) {
+	cmd.Flags().Int64P("priority", "p", 0, "priority of the rule. Rules with lower priority are applied first")
+	cmd.Flags().StringP("payee", "y", "", "condition: regular expression the counter party must match")
+	cmd.Flags().StringP("description", "d", "", "condition: regular expression the description must match")
+	cmd.Flags().StringP("account", "a", "", "condition: account key from trackit.yaml")
+	cmd.Flags().String("min", "", "condition: minimum absolute amount")
+	cmd.Flags().String("max", "", "condition: maximum absolute amount")
+	cmd.Flags().String("sign", "", "condition: positive (deposits) or negative (withdrawls)")
+	cmd.Flags().String("from", "", "condition: first date in YYYY-MM-DD format")
+	cmd.Flags().String("to", "", "condition: last date in YYYY-MM-DD format")
+	cmd.Flags().StringP("category", "c", "", "action: name of the category to set")
+	cmd.Flags().BoolP("ignore", "i", false, "action: whether to ignore the amount when summing or aggregating")
+	cmd.Flags().StringSliceP("tag", "t", []string{}, "action: tag to add. Pass multiple --tag flags for multiple tags")
+}
+
+// ruleFromFlags sets the fields of rule for the flags that were passed, then validates it.
+// Passing an empty string clears a condition or action.
+func ruleFromFlags(ctx context.Context, queries *models.Queries, flags *pflag.FlagSet, rule *models.Rule) error {
+	nullString := func(s string) sql.NullString {
+		return sql.NullString{Valid: s != "", String: s}
+	}
+	if flags.Changed("priority") {
+		rule.Priority, _ = flags.GetInt64("priority")
+	}
+	if flags.Changed("payee") {
+		payee, _ := flags.GetString("payee")
+		rule.CounterParty = nullString(payee)
+	}
+	if flags.Changed("description") {
+		description, _ := flags.GetString("description")
+		rule.Description = nullString(description)
+	}
+	if flags.Changed("account") {
+		account, _ := flags.GetString("account")
+		rule.AccountID = sql.NullInt64{}
+		if account != "" {
+			accountId, err := queries.ReadAccountIdByName(ctx, account)
+			if err != nil {
+				return fmt.Errorf("error getting account ID for %s: %w", account, err)
+			}
+			rule.AccountID = sql.NullInt64{Valid: true, Int64: accountId}
+		}
+	}
+	for _, name := range []string{"min", "max"} {
+		if !flags.Changed(name) {
+			continue
+		}
+		value, _ := flags.GetString(name)
+		amount := sql.NullFloat64{}
+		if value != "" {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("--%s must be a number: %w", name, err)
+			}
+			amount = sql.NullFloat64{Valid: true, Float64: f}
+		}
+		if name == "min" {
+			rule.MinAmount = amount
+		} else {
+			rule.MaxAmount = amount
+		}
+	}
+	if flags.Changed("sign") {
+		sign, _ := flags.GetString("sign")
+		rule.Sign = nullString(sign)
+	}
+	if flags.Changed("from") {
+		from, _ := flags.GetString("from")
+		rule.FromDate = nullString(from)
+	}
+	if flags.Changed("to") {
+		to, _ := flags.GetString("to")
+		rule.ToDate = nullString(to)
+	}
+	if flags.Changed("category") {
+		category, _ := flags.GetString("category")
+		rule.CategoryID = sql.NullInt64{}
+		if category != "" {
+			categoryId, err := queries.ReadCategoryIdByName(ctx, category)
+			if err != nil {
+				return fmt.Errorf("error getting category ID for %s. Do trackit category list to see existing categories: %w", category, err)
+			}
+			rule.CategoryID = sql.NullInt64{Valid: true, Int64: categoryId}
+		}
+	}
+	if flags.Changed("ignore") {
+		ignore, _ := flags.GetBool("ignore")
+		rule.IgnoreWhenSumming = sql.NullInt64{Valid: true}
+		if ignore {
+			rule.IgnoreWhenSumming.Int64 = 1
+		}
+	}
+	if flags.Changed("tag") {
+		tags, _ := flags.GetStringSlice("tag")
+		rule.Tags = nullString(strings.Join(splitTags(strings.Join(tags, ",")), ","))
+	}
+	return validateRule(*rule)
+}
+
+func validateRule(rule models.Rule) error {
+	if !rule.CounterParty.Valid && !rule.Description.Valid && !rule.AccountID.Valid && !rule.MinAmount.Valid &&
+		!rule.MaxAmount.Valid && !rule.Sign.Valid && !rule.FromDate.Valid && !rule.ToDate.Valid {
+		return errors.New("a rule needs at least one condition: --payee, --description, --account, --min, --max, --sign, --from or --to")
+	}
+	if !rule.CategoryID.Valid && !rule.IgnoreWhenSumming.Valid && !rule.Tags.Valid {
+		return errors.New("a rule needs at least one action: --category, --ignore or --tag")
+	}
+	for _, re := range []sql.NullString{rule.CounterParty, rule.Description} {
+		if _, err := regexp.Compile(re.String); re.Valid && err != nil {
+			return fmt.Errorf("invalid regular expression '%s': %w", re.String, err)
+		}
+	}
+	if rule.Sign.Valid && rule.Sign.String != "positive" && rule.Sign.String != "negative" {
+		return fmt.Errorf("sign '%s' is invalid. Must be positive or negative", rule.Sign.String)
+	}
+	for _, date := range []sql.NullString{rule.FromDate, rule.ToDate} {
+		if date.Valid && !validateDateWithDayFormat(date.String) {
+			return fmt.Errorf("date '%s' is invalid. Must be in form: YYYY-MM-DD", date.String)
+		}
+	}
+	if rule.MinAmount.Valid && rule.MaxAmount.Valid && rule.MinAmount.Float64 > rule.MaxAmount.Float64 {
+		return errors.New("--min must not be greater than --max")
+	}
+	return nil
+}
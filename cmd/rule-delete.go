@@ -0,0 +1,46 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var ruleDeleteCmd = &cobra.Command{
+	Use:     "delete",
+	Aliases: []string{"rm"},
+	Args:    cobra.ExactArgs(1),
+	Short:   "Deletes a categorization rule. trackit rule delete <id>",
+	Long: `Deletes a categorization rule by ID. Get the rule ID by doing trackit rule list. Transactions
+the rule already categorized keep their category.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("error parsing id: %w", err)
+		}
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		queries := models.New(db)
+		if err := queries.DeleteRule(context.Background(), id); err != nil {
+			return fmt.Errorf("error deleting rule: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	ruleCmd.AddCommand(ruleDeleteCmd)
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/kahunacohen/trackit/internal/config"
+	"github.com/kahunacohen/trackit/internal/models"
+)
+
+// ruleTransaction holds the fields of a transaction that rules match on.
+type ruleTransaction struct {
+	AccountName  string
+	Amount       float64
+	CounterParty string
+	Date         string
+	Description  string
+}
+
+// ruleActions is what the matching rules do to a transaction.
+type ruleActions struct {
+	// Category is the name of the category to set, if a rule sets one.
+	Category *string
+	Ignore   *bool
+	Tags     []string
+}
+
+type compiledRule struct {
+	models.ReadAllRulesRow
+	counterParty *regexp.Regexp
+	description  *regexp.Regexp
+}
+
+// configRule is a regular expression from trackit.yaml, and the category or tag it sets.
+type configRule struct {
+	name string
+	re   *regexp.Regexp
+}
+
+func (r configRule) matches(t ruleTransaction) bool {
+	return r.re.MatchString(t.CounterParty) || (t.Description != "" && r.re.MatchString(t.Description))
+}
+
+// ruleEngine categorizes transactions. Rules from the database are applied in priority
+// order, then the categories regular expressions in trackit.yaml are tried, sorted by
+// category name, for transactions no rule categorized. Tags whose regular expressions in
+// trackit.yaml match are always added. Regular expressions are compiled once, when the
+// engine is created.
+type ruleEngine struct {
+	rules       []compiledRule
+	configRules []configRule
+	configTags  []configRule
+}
+
+func newRuleEngine(ctx context.Context, queries *models.Queries, conf *config.Config) (*ruleEngine, error) {
+	rows, err := queries.ReadAllRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading rules: %w", err)
+	}
+	engine := &ruleEngine{}
+	for _, row := range rows {
+		rule := compiledRule{ReadAllRulesRow: row}
+		if row.CounterParty.Valid {
+			if rule.counterParty, err = regexp.Compile(row.CounterParty.String); err != nil {
+				return nil, fmt.Errorf("rule %d has an invalid counter party regular expression: %w", row.ID, err)
+			}
+		}
+		if row.Description.Valid {
+			if rule.description, err = regexp.Compile(row.Description.String); err != nil {
+				return nil, fmt.Errorf("rule %d has an invalid description regular expression: %w", row.ID, err)
+			}
+		}
+		engine.rules = append(engine.rules, rule)
+	}
+	if conf == nil {
+		return engine, nil
+	}
+	if engine.configRules, err = compileConfigRules(conf.Categories, "category"); err != nil {
+		return nil, err
+	}
+	// Child categories are written Parent:Child in trackit.yaml.
+	for i, rule := range engine.configRules {
+		engine.configRules[i].name = categoryLeafName(rule.name)
+	}
+	if engine.configTags, err = compileConfigRules(conf.Tags, "tag"); err != nil {
+		return nil, err
+	}
+	return engine, nil
+}
+
+// compileConfigRules compiles the regular expressions of the categories or tags in
+// trackit.yaml, sorted by name.
+func compileConfigRules(regexps map[string][]string, kind string) ([]configRule, error) {
+	names := make([]string, 0, len(regexps))
+	for name := range regexps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var rules []configRule
+	for _, name := range names {
+		for _, regexpStr := range regexps[name] {
+			re, err := regexp.Compile(regexpStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression '%s' for %s %s in trackit.yaml: %w", regexpStr, kind, name, err)
+			}
+			rules = append(rules, configRule{name: name, re: re})
+		}
+	}
+	return rules, nil
+}
+
+func (e *ruleEngine) apply(t ruleTransaction) ruleActions {
+	var actions ruleActions
+	for _, rule := range e.rules {
+		if !rule.matches(t) {
+			continue
+		}
+		if actions.Category == nil && rule.CategoryName.Valid {
+			category := rule.CategoryName.String
+			actions.Category = &category
+		}
+		if actions.Ignore == nil && rule.IgnoreWhenSumming.Valid {
+			ignore := rule.IgnoreWhenSumming.Int64 == 1
+			actions.Ignore = &ignore
+		}
+		for _, tag := range splitTags(rule.Tags.String) {
+			if !slices.Contains(actions.Tags, tag) {
+				actions.Tags = append(actions.Tags, tag)
+			}
+		}
+	}
+	if actions.Category == nil {
+		for _, rule := range e.configRules {
+			if rule.matches(t) {
+				category := rule.name
+				actions.Category = &category
+				break
+			}
+		}
+	}
+	for _, rule := range e.configTags {
+		if rule.matches(t) && !slices.Contains(actions.Tags, rule.name) {
+			actions.Tags = append(actions.Tags, rule.name)
+		}
+	}
+	return actions
+}
+
+// matches reports whether every condition set on the rule holds for the transaction. The
+// amount range is compared against the absolute amount; the sign condition restricts
+// the direction.
+func (r compiledRule) matches(t ruleTransaction) bool {
+	if r.counterParty != nil && !r.counterParty.MatchString(t.CounterParty) {
+		return false
+	}
+	if r.description != nil && !r.description.MatchString(t.Description) {
+		return false
+	}
+	if r.AccountID.Valid && r.AccountName.String != t.AccountName {
+		return false
+	}
+	if r.MinAmount.Valid && math.Abs(t.Amount) < r.MinAmount.Float64 {
+		return false
+	}
+	if r.MaxAmount.Valid && math.Abs(t.Amount) > r.MaxAmount.Float64 {
+		return false
+	}
+	if r.Sign.Valid {
+		if r.Sign.String == "positive" && t.Amount <= 0 {
+			return false
+		}
+		if r.Sign.String == "negative" && t.Amount >= 0 {
+			return false
+		}
+	}
+	if r.FromDate.Valid && t.Date < r.FromDate.String {
+		return false
+	}
+	if r.ToDate.Valid && t.Date > r.ToDate.String {
+		return false
+	}
+	return true
+}
+
+// addTransactionTags links a transaction to tags by name, creating the tags that don't
+// exist yet.
+func addTransactionTags(ctx context.Context, queries *models.Queries, transactionID int64, tags []string) error {
+	for _, tag := range tags {
+		if err := queries.CreateTag(ctx, tag); err != nil {
+			return fmt.Errorf("error creating tag %s: %w", tag, err)
+		}
+		tagID, err := queries.ReadTagIdByName(ctx, tag)
+		if err != nil {
+			return fmt.Errorf("error getting tag ID for %s: %w", tag, err)
+		}
+		err = queries.CreateTransactionTag(ctx, models.CreateTransactionTagParams{TransactionID: transactionID, TagID: tagID})
+		if err != nil {
+			return fmt.Errorf("error tagging transaction %d with %s: %w", transactionID, tag, err)
+		}
+	}
+	return nil
+}
+
+// splitTags splits a comma separated list of tags.
+func splitTags(tags string) []string {
+	var ret []string
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			ret = append(ret, tag)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"database/sql"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/kahunacohen/trackit/internal/models"
+)
+
+func TestCompiledRuleMatches(t *testing.T) {
+	text := func(s string) sql.NullString { return sql.NullString{Valid: true, String: s} }
+	amount := func(f float64) sql.NullFloat64 { return sql.NullFloat64{Valid: true, Float64: f} }
+	transaction := ruleTransaction{
+		AccountName:  "visa",
+		Amount:       -54.20,
+		CounterParty: "WHOLE FOODS #123",
+		Date:         "2026-03-15",
+		Description:  "groceries",
+	}
+	tests := []struct {
+		name    string
+		rule    compiledRule
+		matches bool
+	}{
+		{"empty rule", compiledRule{}, true},
+		{"counter party", compiledRule{counterParty: regexp.MustCompile(`(?i)whole foods`)}, true},
+		{"other counter party", compiledRule{counterParty: regexp.MustCompile(`SHUFERSAL`)}, false},
+		{"description", compiledRule{description: regexp.MustCompile(`^groc`)}, true},
+		{"other description", compiledRule{description: regexp.MustCompile(`^fuel`)}, false},
+		{"account", compiledRule{ReadAllRulesRow: models.ReadAllRulesRow{AccountID: sql.NullInt64{Valid: true, Int64: 1}, AccountName: text("visa")}}, true},
+		{"other account", compiledRule{ReadAllRulesRow: models.ReadAllRulesRow{AccountID: sql.NullInt64{Valid: true, Int64: 2}, AccountName: text("checking")}}, false},
+		{"absolute amount in range", compiledRule{ReadAllRulesRow: models.ReadAllRulesRow{MinAmount: amount(50), MaxAmount: amount(60)}}, true},
+		{"amount at minimum", compiledRule{ReadAllRulesRow: models.ReadAllRulesRow{MinAmount: amount(54.20)}}, true},
+		{"amount below minimum", compiledRule{ReadAllRulesRow: models.ReadAllRulesRow{MinAmount: amount(100)}}, false},
+		{"amount above maximum", compiledRule{ReadAllRulesRow: models.ReadAllRulesRow{MaxAmount: amount(50)}}, false},
+		{"negative sign", compiledRule{ReadAllRulesRow: models.ReadAllRulesRow{Sign: text("negative")}}, true},
+		{"positive sign", compiledRule{ReadAllRulesRow: models.ReadAllRulesRow{Sign: text("positive")}}, false},
+		{"date in range", compiledRule{ReadAllRulesRow: models.ReadAllRulesRow{FromDate: text("2026-03-01"), ToDate: text("2026-03-15")}}, true},
+		{"date before range", compiledRule{ReadAllRulesRow: models.ReadAllRulesRow{FromDate: text("2026-04-01")}}, false},
+		{"date after range", compiledRule{ReadAllRulesRow: models.ReadAllRulesRow{ToDate: text("2026-03-14")}}, false},
+		{
+			"every condition has to hold",
+			compiledRule{
+				ReadAllRulesRow: models.ReadAllRulesRow{Sign: text("negative"), MinAmount: amount(100)},
+				counterParty:    regexp.MustCompile(`WHOLE FOODS`),
+			},
+			false,
+		},
+	}
+	for _, test := range tests {
+		if matches := test.rule.matches(transaction); matches != test.matches {
+			t.Errorf("%s: matches = %v, want %v", test.name, matches, test.matches)
+		}
+	}
+}
+
+func TestConfigRuleMatches(t *testing.T) {
+	rule := configRule{name: "Groceries", re: regexp.MustCompile(`(?i)foods`)}
+	tests := []struct {
+		transaction ruleTransaction
+		matches     bool
+	}{
+		{ruleTransaction{CounterParty: "Whole Foods"}, true},
+		{ruleTransaction{CounterParty: "Shufersal", Description: "frozen foods"}, true},
+		{ruleTransaction{CounterParty: "Shufersal", Description: "fuel"}, false},
+		{ruleTransaction{}, false},
+	}
+	for _, test := range tests {
+		if matches := rule.matches(test.transaction); matches != test.matches {
+			t.Errorf("matches(%+v) = %v, want %v", test.transaction, matches, test.matches)
+		}
+	}
+}
+
+func TestCompileConfigRules(t *testing.T) {
+	rules, err := compileConfigRules(map[string][]string{
+		"Transportation:Fuel": {"PAZ", "SONOL"},
+		"Groceries":           {"SHUFERSAL"},
+	}, "category")
+	if err != nil {
+		t.Fatalf("compileConfigRules returned error: %v", err)
+	}
+	var got []string
+	for _, rule := range rules {
+		got = append(got, rule.name+"="+rule.re.String())
+	}
+	want := []string{"Groceries=SHUFERSAL", "Transportation:Fuel=PAZ", "Transportation:Fuel=SONOL"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("compileConfigRules = %q, want %q", got, want)
+	}
+	if _, err := compileConfigRules(map[string][]string{"vacation": {"("}}, "tag"); err == nil {
+		t.Error("compileConfigRules with an invalid regular expression returned no error")
+	}
+}
+
+func TestRuleEngineApply(t *testing.T) {
+	text := func(s string) sql.NullString { return sql.NullString{Valid: true, String: s} }
+	ignore := sql.NullInt64{Valid: true, Int64: 1}
+	engine := &ruleEngine{
+		rules: []compiledRule{
+			{
+				ReadAllRulesRow: models.ReadAllRulesRow{CategoryName: text("Transfers"), IgnoreWhenSumming: ignore, Tags: text("transfer, savings")},
+				counterParty:    regexp.MustCompile(`^TRANSFER`),
+			},
+			{
+				ReadAllRulesRow: models.ReadAllRulesRow{CategoryName: text("Income"), Tags: text("savings,interest")},
+				description:     regexp.MustCompile(`interest`),
+			},
+		},
+		configRules: []configRule{
+			{name: "Fuel", re: regexp.MustCompile(`PAZ`)},
+			{name: "Groceries", re: regexp.MustCompile(`PAZ|SHUFERSAL`)},
+		},
+		configTags: []configRule{
+			{name: "savings", re: regexp.MustCompile(`TRANSFER`)},
+			{name: "car", re: regexp.MustCompile(`PAZ`)},
+		},
+	}
+	tests := []struct {
+		transaction ruleTransaction
+		category    string
+		ignore      string
+		tags        []string
+	}{
+		{ruleTransaction{CounterParty: "TRANSFER TO SAVINGS", Description: "interest"}, "Transfers", "true", []string{"transfer", "savings", "interest"}},
+		{ruleTransaction{CounterParty: "BANK", Description: "interest"}, "Income", "", []string{"savings", "interest"}},
+		{ruleTransaction{CounterParty: "PAZ YELLOW"}, "Fuel", "", []string{"car"}},
+		{ruleTransaction{CounterParty: "SHUFERSAL DEAL"}, "Groceries", "", nil},
+		{ruleTransaction{CounterParty: "AMAZON"}, "", "", nil},
+	}
+	for _, test := range tests {
+		actions := engine.apply(test.transaction)
+		category, ignore := "", ""
+		if actions.Category != nil {
+			category = *actions.Category
+		}
+		if actions.Ignore != nil {
+			ignore = "false"
+			if *actions.Ignore {
+				ignore = "true"
+			}
+		}
+		if category != test.category || ignore != test.ignore || !reflect.DeepEqual(actions.Tags, test.tags) {
+			t.Errorf("apply(%+v) = category %q, ignore %q, tags %q, want %q, %q, %q", test.transaction, category, ignore,
+				actions.Tags, test.category, test.ignore, test.tags)
+		}
+	}
+}
+
+func TestSplitTags(t *testing.T) {
+	tests := []struct {
+		tags  string
+		split []string
+	}{
+		{"", nil},
+		{"vacation", []string{"vacation"}},
+		{" vacation , car,,", []string{"vacation", "car"}},
+	}
+	for _, test := range tests {
+		if split := splitTags(test.tags); !reflect.DeepEqual(split, test.split) {
+			t.Errorf("splitTags(%q) = %q, want %q", test.tags, split, test.split)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var ruleListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "Lists categorization rules in the order they are applied",
+	Long:    `Lists categorization rules in the order they are applied. trackit rule list`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		queries := models.New(db)
+		rules, err := queries.ReadAllRules(context.Background())
+		if err != nil {
+			return fmt.Errorf("error reading rules: %w", err)
+		}
+		r := report{columns: []outputColumn{{key: "id", header: "ID"}, {key: "priority", header: "Priority"}, {key: "conditions", header: "Conditions"}, {key: "actions", header: "Actions"}}}
+		for _, rule := range rules {
+			r.rows = append(r.rows, []any{rule.ID, rule.Priority, describeRuleConditions(rule), describeRuleActions(rule)})
+		}
+		return printReport(r)
+	},
+}
+
+func init() {
+	ruleCmd.AddCommand(ruleListCmd)
+}
+
+func describeRuleConditions(rule models.ReadAllRulesRow) []string {
+	var conditions []string
+	if rule.CounterParty.Valid {
+		conditions = append(conditions, fmt.Sprintf("payee ~ /%s/", rule.CounterParty.String))
+	}
+	if rule.Description.Valid {
+		conditions = append(conditions, fmt.Sprintf("description ~ /%s/", rule.Description.String))
+	}
+	if rule.AccountID.Valid {
+		conditions = append(conditions, fmt.Sprintf("account = %s", rule.AccountName.String))
+	}
+	if rule.MinAmount.Valid {
+		conditions = append(conditions, fmt.Sprintf("|amount| >= %.2f", rule.MinAmount.Float64))
+	}
+	if rule.MaxAmount.Valid {
+		conditions = append(conditions, fmt.Sprintf("|amount| <= %.2f", rule.MaxAmount.Float64))
+	}
+	if rule.Sign.Valid {
+		conditions = append(conditions, fmt.Sprintf("sign = %s", rule.Sign.String))
+	}
+	if rule.FromDate.Valid {
+		conditions = append(conditions, fmt.Sprintf("date >= %s", rule.FromDate.String))
+	}
+	if rule.ToDate.Valid {
+		conditions = append(conditions, fmt.Sprintf("date <= %s", rule.ToDate.String))
+	}
+	return conditions
+}
+
+func describeRuleActions(rule models.ReadAllRulesRow) []string {
+	var actions []string
+	if rule.CategoryID.Valid {
+		actions = append(actions, fmt.Sprintf("category = %s", rule.CategoryName.String))
+	}
+	if rule.IgnoreWhenSumming.Valid {
+		if rule.IgnoreWhenSumming.Int64 == 1 {
+			actions = append(actions, "ignore")
+		} else {
+			actions = append(actions, "don't ignore")
+		}
+	}
+	if rule.Tags.Valid {
+		actions = append(actions, fmt.Sprintf("tags = %s", rule.Tags.String))
+	}
+	return actions
+}
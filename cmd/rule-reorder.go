@@ -0,0 +1,78 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var ruleReorderCmd = &cobra.Command{
+	Use:   "reorder",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Reorders categorization rules. trackit rule reorder <id> [<id>...]",
+	Long: `Reorders categorization rules. The rules passed are applied first, in the order passed,
+followed by the remaining rules in their current order. Priorities are renumbered from 1. E.g.
+to apply rule 7 before all others:
+
+$ trackit rule reorder 7`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var order []int64
+		for _, arg := range args {
+			id, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return fmt.Errorf("error parsing id %s: %w", arg, err)
+			}
+			if !slices.Contains(order, id) {
+				order = append(order, id)
+			}
+		}
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		ctx := context.Background()
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("error beginning db transaction: %w", err)
+		}
+		defer tx.Rollback()
+		queries := models.New(tx)
+		rules, err := queries.ReadAllRules(ctx)
+		if err != nil {
+			return fmt.Errorf("error reading rules: %w", err)
+		}
+		for _, id := range order {
+			if !slices.ContainsFunc(rules, func(rule models.ReadAllRulesRow) bool { return rule.ID == id }) {
+				return fmt.Errorf("no rule with ID %d", id)
+			}
+		}
+		for _, rule := range rules {
+			if !slices.Contains(order, rule.ID) {
+				order = append(order, rule.ID)
+			}
+		}
+		for i, id := range order {
+			err := queries.UpdateRulePriority(ctx, models.UpdateRulePriorityParams{Priority: int64(i + 1), ID: id})
+			if err != nil {
+				return fmt.Errorf("error updating priority of rule %d: %w", id, err)
+			}
+		}
+		return tx.Commit()
+	},
+}
+
+func init() {
+	ruleCmd.AddCommand(ruleReorderCmd)
+}
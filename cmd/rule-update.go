@@ -0,0 +1,69 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var ruleUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Args:  cobra.ExactArgs(1),
+	Short: "Updates a categorization rule. trackit rule update <id> [flags]",
+	Long: `Updates a categorization rule by ID. Only the conditions and actions passed as flags change.
+Pass an empty string to remove a condition or action, e.g. trackit rule update 3 --account ""`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("error parsing id: %w", err)
+		}
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		ctx := context.Background()
+		queries := models.New(db)
+		rule, err := queries.ReadRuleById(ctx, id)
+		if err != nil {
+			return fmt.Errorf("error getting rule %d: %w", id, err)
+		}
+		if err := ruleFromFlags(ctx, queries, cmd.Flags(), &rule); err != nil {
+			return err
+		}
+		err = queries.UpdateRule(ctx, models.UpdateRuleParams{
+			Priority:          rule.Priority,
+			CounterParty:      rule.CounterParty,
+			Description:       rule.Description,
+			AccountID:         rule.AccountID,
+			MinAmount:         rule.MinAmount,
+			MaxAmount:         rule.MaxAmount,
+			Sign:              rule.Sign,
+			FromDate:          rule.FromDate,
+			ToDate:            rule.ToDate,
+			CategoryID:        rule.CategoryID,
+			IgnoreWhenSumming: rule.IgnoreWhenSumming,
+			Tags:              rule.Tags,
+			ID:                rule.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("error updating rule: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	addRuleFlags(ruleUpdateCmd)
+	ruleCmd.AddCommand(ruleUpdateCmd)
+}
@@ -0,0 +1,24 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var ruleCmd = &cobra.Command{
+	Use:   "rule",
+	Short: "Manages categorization rules",
+	Long: `Manages categorization rules. Rules are applied in priority order (lowest first) when
+transactions are imported or created. A rule matches when all of its conditions hold. The first
+matching rule that sets a category (or the ignore flag) wins, and tags are added from every
+matching rule. Transactions no rule categorizes fall back to the categories in trackit.yaml.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(ruleCmd)
+}
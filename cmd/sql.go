@@ -0,0 +1,327 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/spf13/cobra"
+)
+
+var sqlCmd = &cobra.Command{
+	Use:   "sql",
+	Args:  cobra.MaximumNArgs(1),
+	Short: "Runs SQL against trackit.db. trackit sql [<statements>]",
+	Long: `Runs SQL statements against trackit.db, without needing the sqlite3 CLI. Pass the statements,
+separated by semicolons, or a file of them with --file. Without either, statements are read from
+stdin, one after another, as in the sqlite3 CLI. E.g.:
+
+$ trackit sql "SELECT category_name, SUM(amount) FROM transactions_view GROUP BY 1"
+$ trackit sql --file custom.sql --output csv
+$ trackit sql
+
+The database is opened read-only, unless --write is passed. Results are printed as a table, or in
+the format of --output. The transactions_view view has the transactions with their account, category
+and payee names, and REGEXP matches Go regular expressions, as rules do.
+
+When reading statements from stdin, these commands are also available:
+
+.tables          Lists the tables and views
+.schema [<name>] Prints the CREATE statements of all tables, or of one
+.help            Prints this help
+.quit            Exits`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		write, _ := cmd.Flags().GetBool("write")
+		if file != "" && len(args) > 0 {
+			return errors.New("pass either statements or --file, not both")
+		}
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		// Opened with getDB first, so the database exists and is migrated.
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		if !write {
+			db.Close()
+			db, err = sql.Open(sqliteDriver, fmt.Sprintf("file:%s?mode=ro", dbPath))
+			if err != nil {
+				return fmt.Errorf("error opening database read-only: %w", err)
+			}
+		}
+		defer db.Close()
+		// One connection, so that statements like BEGIN, and temporary tables, last across
+		// statements.
+		db.SetMaxOpenConns(1)
+		ctx := context.Background()
+		switch {
+		case len(args) > 0:
+			return runSQLScript(ctx, db, args[0])
+		case file != "":
+			b, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("error reading %s: %w", file, err)
+			}
+			return runSQLScript(ctx, db, string(b))
+		}
+		return runSQLConsole(ctx, db, cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sqlCmd)
+	sqlCmd.Flags().StringP("file", "f", "", "Run the statements in this file")
+	sqlCmd.Flags().BoolP("write", "w", false, "Open the database for writing, so statements can change it")
+}
+
+// runSQLScript runs statements separated by semicolons, stopping at the first error.
+func runSQLScript(ctx context.Context, db *sql.DB, script string) error {
+	statements, rest := splitSQLStatements(script)
+	if rest = strings.TrimSpace(rest); rest != "" {
+		// The last statement doesn't need a semicolon.
+		statements = append(statements, rest)
+	}
+	for _, statement := range statements {
+		if err := runSQLStatement(ctx, db, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runSQLConsole reads statements from stdin and runs them as they're completed. From a
+// terminal, it prompts for them, and errors are printed instead of ending it.
+func runSQLConsole(ctx context.Context, db *sql.DB, cmd *cobra.Command) error {
+	stat, err := os.Stdin.Stat()
+	interactive := err == nil && stat.Mode()&os.ModeCharDevice != 0
+	if interactive {
+		fmt.Println(`Enter SQL statements ending with ";", or .help for help`)
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	var pending string
+	for {
+		if interactive {
+			if pending == "" {
+				fmt.Print("trackit> ")
+			} else {
+				fmt.Print("    ...> ")
+			}
+		}
+		if !scanner.Scan() {
+			break
+		}
+		line := scanner.Text()
+		var err error
+		if pending == "" && strings.HasPrefix(strings.TrimSpace(line), ".") {
+			var quit bool
+			quit, err = runSQLConsoleCommand(ctx, db, cmd, strings.Fields(line))
+			if quit {
+				return nil
+			}
+		} else {
+			var statements []string
+			statements, pending = splitSQLStatements(pending + line + "\n")
+			for _, statement := range statements {
+				if err = runSQLStatement(ctx, db, statement); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			if !interactive {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading stdin: %w", err)
+	}
+	if interactive {
+		fmt.Println()
+		return nil
+	}
+	return runSQLScript(ctx, db, pending)
+}
+
+// runSQLConsoleCommand runs a console command, like .tables, and reports whether it's the
+// one to quit.
+func runSQLConsoleCommand(ctx context.Context, db *sql.DB, cmd *cobra.Command, fields []string) (bool, error) {
+	switch fields[0] {
+	case ".quit", ".exit":
+		return true, nil
+	case ".help":
+		fmt.Println(cmd.Long)
+	case ".tables":
+		return false, runSQLStatement(ctx, db, `SELECT "name", type FROM sqlite_master
+WHERE type IN ('table', 'view') AND "name" NOT LIKE 'sqlite_%' AND "name" NOT LIKE 'transactions_fts_%'
+ORDER BY "name"`)
+	case ".schema":
+		query := `SELECT "sql" FROM sqlite_master WHERE "sql" IS NOT NULL AND "name" NOT LIKE 'transactions_fts_%'`
+		var args []any
+		if len(fields) > 1 {
+			query += ` AND ("name"=? OR tbl_name=?)`
+			args = append(args, fields[1], fields[1])
+		}
+		rows, err := db.QueryContext(ctx, query+" ORDER BY tbl_name, type DESC", args...)
+		if err != nil {
+			return false, fmt.Errorf("error reading schema: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var statement string
+			if err := rows.Scan(&statement); err != nil {
+				return false, fmt.Errorf("error reading schema: %w", err)
+			}
+			fmt.Printf("%s;\n", statement)
+		}
+		return false, rows.Err()
+	default:
+		return false, fmt.Errorf("unknown command %s. Enter .help for help", fields[0])
+	}
+	return false, nil
+}
+
+// runSQLStatement runs a statement, printing the rows it returns, or how many rows it
+// changed.
+func runSQLStatement(ctx context.Context, db *sql.DB, statement string) error {
+	rows, err := db.QueryContext(ctx, statement)
+	if err != nil {
+		return sqlError(err)
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return sqlError(err)
+	}
+	if len(columns) == 0 {
+		// Statements that don't return rows, e.g. UPDATE, are only run by Exec, which
+		// reports the rows they change.
+		rows.Close()
+		result, err := db.ExecContext(ctx, statement)
+		if err != nil {
+			return sqlError(err)
+		}
+		keyword := strings.ToUpper(strings.Fields(statement)[0])
+		if slices.Contains([]string{"INSERT", "UPDATE", "DELETE", "REPLACE"}, keyword) {
+			changed, _ := result.RowsAffected()
+			fmt.Fprintf(os.Stderr, "%d rows changed\n", changed)
+		}
+		return nil
+	}
+	defer rows.Close()
+	var r report
+	for _, column := range columns {
+		r.columns = append(r.columns, outputColumn{key: column, header: column})
+	}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return sqlError(err)
+		}
+		for i, value := range values {
+			if b, ok := value.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		r.rows = append(r.rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return sqlError(err)
+	}
+	return printReport(r)
+}
+
+// sqlError explains errors from writing to the database when it's opened read-only.
+func sqlError(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrReadonly {
+		return fmt.Errorf("%w. The database is opened read-only, pass --write to change it", err)
+	}
+	return err
+}
+
+// splitSQLStatements splits SQL into the statements ending with semicolons, and the rest
+// after the last of them, which is empty if it's only whitespace and comments. Semicolons in
+// quotes, comments and the body of CREATE TRIGGER statements don't end statements.
+func splitSQLStatements(script string) ([]string, string) {
+	var statements []string
+	start := 0
+	code := false
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		var open, end string
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			open, end = string(c), string(c)
+		case c == '[':
+			open, end = "[", "]"
+		case strings.HasPrefix(script[i:], "--"):
+			open, end = "--", "\n"
+		case strings.HasPrefix(script[i:], "/*"):
+			open, end = "/*", "*/"
+		}
+		if open != "" {
+			j := strings.Index(script[i+len(open):], end)
+			if j < 0 {
+				// Unterminated, so the statement isn't complete, unless it's a line comment.
+				if end != "\n" {
+					return statements, script[start:]
+				}
+				j = len(script) - i - len(open)
+			}
+			code = code || (open != "--" && open != "/*")
+			i += len(open) + j + len(end) - 1
+			continue
+		}
+		if c != ';' {
+			code = code || !strings.ContainsRune(" \t\r\n", rune(c))
+			continue
+		}
+		statement := strings.TrimSpace(script[start:i])
+		if isSQLTrigger(statement) {
+			fields := strings.Fields(strings.ToUpper(statement))
+			if fields[len(fields)-1] != "END" {
+				continue
+			}
+		}
+		if code {
+			statements = append(statements, statement)
+		}
+		start = i + 1
+		code = false
+	}
+	if !code {
+		return statements, ""
+	}
+	return statements, script[start:]
+}
+
+// isSQLTrigger reports whether a statement creates a trigger, whose body has statements of
+// its own.
+func isSQLTrigger(statement string) bool {
+	fields := strings.Fields(strings.ToUpper(statement))
+	if len(fields) < 2 || fields[0] != "CREATE" {
+		return false
+	}
+	if len(fields) > 2 && (fields[1] == "TEMP" || fields[1] == "TEMPORARY") {
+		return fields[2] == "TRIGGER"
+	}
+	return fields[1] == "TRIGGER"
+}
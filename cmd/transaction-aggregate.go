@@ -6,8 +6,11 @@ package cmd
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"sort"
 
 	"github.com/kahunacohen/trackit/internal/models"
 
@@ -21,13 +24,21 @@ var transactionAggregateCmd = &cobra.Command{
 	Long: `Aggregates transactions by some facet (default is category): E.g.
 	
 $ trackit aggregate
-$ trackit aggregate --by account
+$ trackit aggregate --by tag
+$ trackit aggregate --tag vacation-2026
+$ trackit aggregate --depth 1
+$ trackit aggregate --from 2026-01-01 --to 2026-03-31 --account visa --account checking
+
+The transactions aggregated are selected with the same flags as trackit transaction list. Ignored
+transactions are left out, unless they're selected with --ignored or a --where filter on ignored.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		date, _ := cmd.Flags().GetString("date")
-		account, _ := cmd.Flags().GetString("account")
 		by, _ := cmd.Flags().GetString("by")
-		_, _, dbPath, err := getDataPaths()
+		depth, _ := cmd.Flags().GetInt("depth")
+		if depth < 0 {
+			return errors.New("depth can't be negative")
+		}
+		_, configPath, dbPath, err := getDataPaths()
 		if err != nil {
 			return err
 		}
@@ -35,63 +46,102 @@ $ trackit aggregate --by account
 		if err != nil {
 			log.Fatalf("Failed to open database: %v", err)
 		}
+		ctx := context.Background()
+		queries := models.New(db)
+		q, err := transactionQueryFromFlags(ctx, queries, cmd.Flags(), configPath)
+		if err != nil {
+			return err
+		}
 
 		if by == "category" {
-			aggregations, err := getCategoryAggregation(db, account, date)
+			rows, err := readTransactionCategories(ctx, db, q)
 			if err != nil {
 				return fmt.Errorf("error aggregating by category: %w", err)
 			}
-			RenderAggregateTable(aggregations)
-		} else {
-			return fmt.Errorf("aggregation '%s' not implemented yet", by)
+			tree, err := readCategoryTree(ctx, queries)
+			if err != nil {
+				return err
+			}
+			kinds := tree.kinds()
+			aggregations, summary := getCategoryAggregation(rows, kinds)
+			if depth > 0 {
+				aggregations = tree.rollUpAggregation(aggregations, depth)
+			}
+			return printReport(aggregateReport("Category", aggregations, kinds, &summary))
+		} else if by == "tag" {
+			aggregations, err := getTagAggregation(ctx, db, q)
+			if err != nil {
+				return fmt.Errorf("error aggregating by tag: %w", err)
+			}
+			return printReport(aggregateReport("Tag", aggregations, nil, nil))
 		}
-		return nil
+		return fmt.Errorf("aggregation '%s' not implemented yet", by)
 
 	},
 }
 
 func init() {
-	transactionAggregateCmd.Flags().StringP("account", "a", "", "account key from trackit.yaml to filter by account")
-	transactionAggregateCmd.Flags().StringP("date", "d", "", "Date in YYYY-MM format. For now, day precision is not implemented.")
-	transactionAggregateCmd.Flags().StringP("by", "b", "category", "What to aggregate total by")
+	addTransactionFilterFlags(transactionAggregateCmd)
+	transactionAggregateCmd.Flags().StringP("by", "b", "category", "What to aggregate total by: category or tag")
+	transactionAggregateCmd.Flags().Int("depth", 0, "Add the totals of child categories deeper than this to their ancestors. 1 totals top level categories only")
 	transactionCmd.AddCommand(transactionAggregateCmd)
 }
 
-func getCategoryAggregation(db *sql.DB, account string, date string) ([]models.AggregateTransactionsRow, error) {
-	queries := models.New(db)
-	ctx := context.Background()
-	var err error
-	var rows []models.AggregateTransactionsRow
-	if account == "" && date == "" {
-		rows, err = queries.AggregateTransactions(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("error aggregating rows: %w", err)
-		}
-	} else if account != "" && date == "" {
-		xs, err := queries.AggregateTransactionsByAccountName(ctx, sql.NullString{Valid: true, String: account})
-		if err != nil {
-			return nil, fmt.Errorf("error aggreating rows: %w", err)
-		}
-		for _, x := range xs {
-			rows = append(rows, models.AggregateTransactionsRow(x))
+// getCategoryAggregation totals the amounts counted per category by category, and by the
+// kind of their category. Amounts are summed by kind one transaction (or split) at a time,
+// so that uncategorized amounts count as income or expenses by their own sign.
+func getCategoryAggregation(rows []models.TransactionCategoriesView, kinds map[string]string) ([]models.AggregateTransactionsRow, amountSummary) {
+	var summary amountSummary
+	totals := make(map[string]float64)
+	for _, row := range rows {
+		category := "Uncategorized"
+		if row.CategoryName.Valid {
+			category = row.CategoryName.String
 		}
-	} else if account == "" && date != "" {
-		xs, err := queries.AggregateTransactionsByDate(ctx, date)
-		if err != nil {
-			return nil, fmt.Errorf("error aggreating rows: %w", err)
-		}
-		for _, x := range xs {
-			rows = append(rows, models.AggregateTransactionsRow(x))
-		}
-	} else {
-		xs, err := queries.AggregateTransactionsByAccountNameAndDate(ctx,
-			models.AggregateTransactionsByAccountNameAndDateParams{AccountName: sql.NullString{Valid: true, String: account}, Date: date})
-		if err != nil {
-			return nil, fmt.Errorf("error aggreating rows: %w", err)
+		totals[category] += row.Amount
+		summary.add(kinds[row.CategoryName.String], row.Amount)
+	}
+	return sortedAggregation(totals), summary
+}
+
+// getTagAggregation aggregates transactions by tag. A transaction with several tags counts
+// towards each of them.
+func getTagAggregation(ctx context.Context, db *sql.DB, q *transactionQuery) ([]models.AggregateTransactionsRow, error) {
+	transactions, err := readTransactions(ctx, db, q)
+	if err != nil {
+		return nil, err
+	}
+	amounts := make(map[int64]float64)
+	for _, transaction := range transactions {
+		if transaction.IgnoreWhenSumming == 0 || q.ignored {
+			amounts[transaction.TransactionID] = transaction.Amount
 		}
-		for _, x := range xs {
-			rows = append(rows, models.AggregateTransactionsRow(x))
+	}
+	transactionTags, err := models.New(db).ReadAllTransactionTags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading transaction tags: %w", err)
+	}
+	totals := make(map[string]float64)
+	for _, transactionTag := range transactionTags {
+		if amount, ok := amounts[transactionTag.TransactionID]; ok {
+			totals[transactionTag.TagName] += amount
 		}
 	}
-	return rows, nil
+	return sortedAggregation(totals), nil
+}
+
+// sortedAggregation returns the totals rounded to cents and sorted by total, like the
+// aggregation queries.
+func sortedAggregation(totals map[string]float64) []models.AggregateTransactionsRow {
+	var rows []models.AggregateTransactionsRow
+	for name, total := range totals {
+		rows = append(rows, models.AggregateTransactionsRow{CategoryName: name, TotalAmount: math.Round(total*100) / 100})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].TotalAmount == rows[j].TotalAmount {
+			return rows[i].CategoryName < rows[j].CategoryName
+		}
+		return rows[i].TotalAmount < rows[j].TotalAmount
+	})
+	return rows
 }
@@ -10,6 +10,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -19,9 +21,18 @@ import (
 	"github.com/spf13/cobra"
 )
 
-const skipText = "Skip categorizing this transaction"
+const (
+	skipText        = "Skip categorizing these transactions"
+	newCategoryText = "Create a new category"
+	undoText        = "Undo the last choice"
+)
+
+// categorizeSampleSize is how many of a payee's transactions are shown when prompting
+// for their category.
+const categorizeSampleSize = 5
 
-var categoryNames []string
+// bulkCategorizeLimit is how many transactions --where and --ids categorize without --yes.
+const bulkCategorizeLimit = 10
 
 var transactionCategorizeCmd = &cobra.Command{
 	Use:     "categorize",
@@ -36,16 +47,57 @@ var transactionCategorizeCmd = &cobra.Command{
 	Long: `categorize transactions either by interactively categorizing all un-categorized
 transactions (no flags passed), or by categorizing an individual transaction by ID (trackit categorize <transaction_id>).
 Get the transaction ID by doing trackit list. To update existing transaction categories, just run trackit categorize (or)
-trackit categorize <id>`,
+trackit categorize <id>
+
+Un-categorized transactions are prompted for by payee, payees with the most transactions first. The category
+chosen can be applied to all the payee's transactions, and saved as a rule for future imports. Choose
+"Create a new category" to add one (Parent:Child adds a child category), or "Undo the last choice" to take
+the last category chosen back.
+
+The categories suggested by your previous categorizations are listed first, with how confident trackit
+is in them. Pass --auto to assign the suggested category, without prompting, to every un-categorized
+transaction trackit is at least --min-confidence sure of, and whose payee has a word in common with
+transactions of the category. --auto only assigns categories with at least 3 categorized transactions,
+and needs at least 2 such categories.
+
+To categorize without prompting, e.g. in scripts, pass the category's name or ID to --category, and
+choose the transactions with an ID, --ids or a --where filter:
+
+$ trackit transaction categorize 12 --category Groceries
+$ trackit transaction categorize --ids 1,2,3 --category Groceries
+$ trackit transaction categorize --where 'payee ~ "whole foods" and category is null' --category Groceries
+
+Filters compare the fields id, date, account, payee, counter_party, description, amount, category, kind,
+ignored and tag with =, !=, <, <=, >, >=, ~ (contains) and !~ (doesn't contain), or test them with
+is null and is not null, combined with and, or, not and parentheses. Categorizing more than 10
+transactions at once needs --yes.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		auto, _ := cmd.Flags().GetBool("auto")
+		minConfidence, _ := cmd.Flags().GetFloat64("min-confidence")
+		category, _ := cmd.Flags().GetString("category")
+		where, _ := cmd.Flags().GetString("where")
+		ids, _ := cmd.Flags().GetInt64Slice("ids")
+		yes, _ := cmd.Flags().GetBool("yes")
+		if (where != "" || len(ids) > 0) && category == "" {
+			return errors.New("--where and --ids need a --category")
+		}
+		if category != "" && auto {
+			return errors.New("--category and --auto can't be passed together")
+		}
 		var transactionId int
 		var strconvErr error
 		if len(args) == 1 {
+			if auto {
+				return errors.New("--auto categorizes all un-categorized transactions and can't be passed with a transaction ID")
+			}
 			transactionId, strconvErr = strconv.Atoi(args[0])
 			if strconvErr != nil {
 				return fmt.Errorf("error parsing transaction id: %w", strconvErr)
 			}
 		}
+		if minConfidence < 0 || minConfidence > 1 {
+			return errors.New("--min-confidence must be between 0 and 1")
+		}
 		_, _, dbPath, err := getDataPaths()
 		if err != nil {
 			return err
@@ -56,6 +108,15 @@ trackit categorize <id>`,
 		}
 		ctx := context.Background()
 		queries := models.New(db)
+		if category != "" {
+			if transactionId != 0 {
+				ids = append(ids, int64(transactionId))
+			}
+			if where == "" && len(ids) == 0 {
+				return errors.New("--category needs a transaction ID, --ids or --where")
+			}
+			return bulkCategorize(ctx, db, category, where, ids, yes)
+		}
 
 		categories, err := queries.ReadAllCategories(ctx)
 		if err != nil {
@@ -66,52 +127,19 @@ trackit categorize <id>`,
 		for _, category := range categories {
 			categoryMap[category.Name] = category.ID
 		}
-		categoryNames = append(categoryNames, skipText)
+		var categoryNames []string
 		for _, category := range categories {
 			categoryNames = append(categoryNames, category.Name)
 		}
+		classifier, err := newCategoryClassifier(ctx, queries)
+		if err != nil {
+			return err
+		}
+		if auto {
+			return autoCategorize(ctx, db, classifier, categoryMap, minConfidence)
+		}
 		if transactionId == 0 {
-			transactions, err := queries.ReadNonCategorizedTransactions(ctx)
-			if err != nil {
-				return fmt.Errorf("error reading non categorized transactions: %w", err)
-			}
-			for _, transaction := range transactions {
-				t := table.NewWriter()
-				t.SetStyle(table.StyleLight)
-				t.SetOutputMirror(os.Stdout)
-				t.AppendHeader(table.Row{"Date", "Account", "Payee", "Amount"})
-				var accountKey string
-				if transaction.AccountName.Valid {
-					accountKey = transaction.AccountName.String
-				} else {
-					return errors.New("account is null")
-				}
-
-				t.AppendRow([]interface{}{transaction.Date,
-					accountKeyToName(sql.NullString{Valid: true, String: accountKey}),
-					transaction.CounterParty, fmt.Sprintf("%.2f", transaction.Amount)})
-
-				prompt := promptui.Select{
-					Label:             t.Render(),
-					Items:             categoryNames,
-					StartInSearchMode: true,
-					Searcher:          searcher,
-				}
-				_, categoryNameResult, err := prompt.Run()
-				if err != nil {
-					return fmt.Errorf("prompt failed %w", err)
-				}
-				if categoryNameResult == skipText {
-					continue
-				}
-				err = queries.UpdateTransactionCategory(ctx, models.UpdateTransactionCategoryParams{
-					CategoryID: sql.NullInt64{Valid: true, Int64: categoryMap[categoryNameResult]},
-					ID:         transaction.TransactionID})
-				if err != nil {
-					return fmt.Errorf("error setting category: %w", err)
-				}
-			}
-
+			return categorizeInteractively(ctx, queries, classifier, categoryMap, categoryNames)
 		} else {
 			transaction, err := queries.ReadTransactionById(ctx, int64(transactionId))
 			if err != nil {
@@ -122,19 +150,19 @@ trackit categorize <id>`,
 			t.SetOutputMirror(os.Stdout)
 			t.AppendHeader(table.Row{"Date", "Account", "Payee", "Amount"})
 			t.AppendRow([]interface{}{transaction.Date, transaction.AccountName.String, transaction.CounterParty, fmt.Sprintf("%.2f", transaction.Amount)})
-			prompt := promptui.Select{
-				Label:             t.Render(),
-				Items:             categoryNames,
-				StartInSearchMode: true,
-				Searcher:          searcher,
-			}
-			_, categoryNameResult, err := prompt.Run()
+			categoryNameResult, err := promptCategory(t.Render(), categoryNames, classifier.suggest(transaction, 3), newCategoryText)
 			if err != nil {
-				return fmt.Errorf("prompt failed %w", err)
+				return err
+			}
+			if categoryNameResult == newCategoryText {
+				if categoryNameResult, err = promptNewCategory(ctx, queries, categoryMap, &categoryNames); err != nil {
+					return err
+				}
 			}
 			err = queries.UpdateTransactionCategory(ctx, models.UpdateTransactionCategoryParams{
-				CategoryID: sql.NullInt64{Valid: true, Int64: categoryMap[categoryNameResult]},
-				ID:         transaction.TransactionID})
+				CategoryID:          sql.NullInt64{Valid: true, Int64: categoryMap[categoryNameResult]},
+				CategorizedManually: 1,
+				ID:                  transaction.TransactionID})
 			if err != nil {
 				return fmt.Errorf("error setting category: %w", err)
 			}
@@ -145,8 +173,423 @@ trackit categorize <id>`,
 
 func init() {
 	transactionCmd.AddCommand(transactionCategorizeCmd)
+	transactionCategorizeCmd.Flags().Bool("auto", false, "Categorize un-categorized transactions with the suggested category, without prompting")
+	transactionCategorizeCmd.Flags().Float64("min-confidence", 0.9, "With --auto, the minimum confidence, between 0 and 1, to assign a suggested category")
+	transactionCategorizeCmd.Flags().StringP("category", "c", "", "Name or ID of the category to set, without prompting")
+	transactionCategorizeCmd.Flags().StringP("where", "w", "", "With --category, filter of the transactions to categorize, e.g. 'payee ~ shufersal and amount < 0'")
+	transactionCategorizeCmd.Flags().Int64Slice("ids", nil, "With --category, comma separated IDs of the transactions to categorize")
+	transactionCategorizeCmd.Flags().BoolP("yes", "y", false, fmt.Sprintf("Categorize more than %d transactions at once", bulkCategorizeLimit))
+}
+
+// promptCategory prompts for a category, listing the extra items, such as skipText, first
+// and then the suggestions. It returns the name of the category, or the extra item chosen.
+func promptCategory(label string, categoryNames []string, suggestions []categorySuggestion, extras ...string) (string, error) {
+	items := append([]string{}, extras...)
+	names := append([]string{}, extras...)
+	for _, suggestion := range suggestions {
+		items = append(items, fmt.Sprintf("%s (suggested, %.0f%%)", suggestion.Category, suggestion.Confidence*100))
+		names = append(names, suggestion.Category)
+	}
+	for _, name := range categoryNames {
+		if !slices.ContainsFunc(suggestions, func(suggestion categorySuggestion) bool { return suggestion.Category == name }) {
+			items = append(items, name)
+			names = append(names, name)
+		}
+	}
+	prompt := promptui.Select{
+		Label:             label,
+		Items:             items,
+		StartInSearchMode: true,
+		Searcher: func(input string, i int) bool {
+			return strings.Contains(strings.ToLower(items[i]), strings.ToLower(input))
+		},
+	}
+	i, _, err := prompt.Run()
+	if err != nil {
+		return "", fmt.Errorf("prompt failed %w", err)
+	}
+	return names[i], nil
+}
+
+// promptNewCategory prompts for the name of a new category, creates it and adds it to the
+// category names and IDs. A path like Transportation:Fuel creates a child category.
+func promptNewCategory(ctx context.Context, queries *models.Queries, categoryMap map[string]int64, categoryNames *[]string) (string, error) {
+	prompt := promptui.Prompt{
+		Label: "New category (Parent:Child for a child category)",
+		Validate: func(s string) error {
+			if len(splitCategoryPath(s)) == 0 {
+				return errors.New("category name can't be empty")
+			}
+			return nil
+		},
+	}
+	path, err := prompt.Run()
+	if err != nil {
+		return "", fmt.Errorf("prompt failed %w", err)
+	}
+	id, err := createCategoryPath(ctx, queries, path)
+	if err != nil {
+		return "", err
+	}
+	name := categoryLeafName(path)
+	categoryMap[name] = id
+	if !slices.Contains(*categoryNames, name) {
+		*categoryNames = append(*categoryNames, name)
+		slices.Sort(*categoryNames)
+	}
+	return name, nil
+}
+
+// payeeGroup is the un-categorized transactions of one payee, or of counter parties that
+// are the same once normalized.
+type payeeGroup struct {
+	label        string
+	transactions []models.TransactionsView
+}
+
+// categorizeChoice is a category chosen for a payee's transactions, kept so that it can be
+// undone.
+type categorizeChoice struct {
+	group  payeeGroup
+	ids    []int64
+	ruleId int64
+}
+
+// groupByPayee groups transactions by payee, or by normalized counter party if they have
+// no payee. Payees with the most transactions come first.
+func groupByPayee(transactions []models.TransactionsView) []payeeGroup {
+	var groups []payeeGroup
+	indexes := make(map[string]int)
+	for _, transaction := range transactions {
+		key := "counter_party:" + normalizeCounterParty(transaction.CounterParty)
+		if transaction.PayeeID.Valid {
+			key = fmt.Sprintf("payee:%d", transaction.PayeeID.Int64)
+		}
+		i, ok := indexes[key]
+		if !ok {
+			i = len(groups)
+			indexes[key] = i
+			groups = append(groups, payeeGroup{label: payeeName(transaction)})
+		}
+		groups[i].transactions = append(groups[i].transactions, transaction)
+	}
+	slices.SortStableFunc(groups, func(a, b payeeGroup) int {
+		if len(a.transactions) != len(b.transactions) {
+			return len(b.transactions) - len(a.transactions)
+		}
+		return strings.Compare(a.label, b.label)
+	})
+	return groups
+}
+
+// normalizeCounterParty lower cases a counter party and drops the words with digits in them,
+// such as branch numbers, dates and references, that banks add to the same payee's name.
+func normalizeCounterParty(counterParty string) string {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(counterParty)) {
+		if !strings.ContainsAny(word, "0123456789") {
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 {
+		return strings.ToLower(strings.TrimSpace(counterParty))
+	}
+	return strings.Join(words, " ")
+}
+
+// counterPartyPattern returns a regular expression matching the counter party regardless
+// of case and of the words normalizing drops.
+func counterPartyPattern(counterParty string) string {
+	var words []string
+	for _, word := range strings.Fields(normalizeCounterParty(counterParty)) {
+		words = append(words, regexp.QuoteMeta(word))
+	}
+	return "(?i)" + strings.Join(words, ".*")
 }
 
-func searcher(input string, i int) bool {
-	return strings.Contains(strings.ToLower(categoryNames[i]), strings.ToLower(input))
+// categorizeInteractively prompts for the category of the un-categorized transactions, one
+// payee at a time.
+func categorizeInteractively(ctx context.Context, queries *models.Queries, classifier *categoryClassifier, categoryMap map[string]int64, categoryNames []string) error {
+	transactions, err := queries.ReadNonCategorizedTransactions(ctx)
+	if err != nil {
+		return fmt.Errorf("error reading non categorized transactions: %w", err)
+	}
+	queue := groupByPayee(transactions)
+	var history []categorizeChoice
+	for len(queue) > 0 {
+		group := queue[0]
+		queue = queue[1:]
+		extras := []string{skipText, newCategoryText}
+		if len(history) > 0 {
+			extras = append(extras, undoText)
+		}
+		categoryName, err := promptCategory(renderPayeeGroup(group), categoryNames, classifier.suggest(group.transactions[0], 3), extras...)
+		if err != nil {
+			return err
+		}
+		switch categoryName {
+		case skipText:
+			continue
+		case undoText:
+			last := history[len(history)-1]
+			history = history[:len(history)-1]
+			if err := undoCategorizeChoice(ctx, queries, last); err != nil {
+				return err
+			}
+			queue = requeuePayeeGroup(append([]payeeGroup{group}, queue...), last.group)
+			fmt.Printf("un-categorized %d transactions from %s\n", len(last.ids), last.group.label)
+			continue
+		case newCategoryText:
+			if categoryName, err = promptNewCategory(ctx, queries, categoryMap, &categoryNames); err != nil {
+				return err
+			}
+		}
+		choice := categorizeChoice{group: group}
+		categorize := group.transactions
+		if len(group.transactions) > 1 {
+			applyPrompt := promptui.Prompt{
+				Label:     fmt.Sprintf("Categorize all %d transactions from %s as %s", len(group.transactions), group.label, categoryName),
+				IsConfirm: true,
+				Default:   "y",
+			}
+			if _, err := applyPrompt.Run(); err != nil {
+				if !errors.Is(err, promptui.ErrAbort) {
+					return fmt.Errorf("prompt failed %w", err)
+				}
+				categorize = group.transactions[:1]
+				queue = append([]payeeGroup{{label: group.label, transactions: group.transactions[1:]}}, queue...)
+			}
+		}
+		for _, transaction := range categorize {
+			err := queries.UpdateTransactionCategory(ctx, models.UpdateTransactionCategoryParams{
+				CategoryID:          sql.NullInt64{Valid: true, Int64: categoryMap[categoryName]},
+				CategorizedManually: 1,
+				ID:                  transaction.TransactionID})
+			if err != nil {
+				return fmt.Errorf("error setting category: %w", err)
+			}
+			choice.ids = append(choice.ids, transaction.TransactionID)
+		}
+		if choice.ruleId, err = promptCategoryRule(ctx, queries, group, categoryMap[categoryName]); err != nil {
+			return err
+		}
+		history = append(history, choice)
+	}
+	return nil
+}
+
+// renderPayeeGroup renders the first transactions of a payee and how many there are.
+func renderPayeeGroup(group payeeGroup) string {
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.AppendHeader(table.Row{"Date", "Account", "Payee", "Amount"})
+	for i, transaction := range group.transactions {
+		if i == categorizeSampleSize {
+			t.AppendFooter(table.Row{fmt.Sprintf("and %d more", len(group.transactions)-categorizeSampleSize)})
+			break
+		}
+		t.AppendRow(table.Row{transaction.Date, accountKeyToName(transaction.AccountName),
+			transaction.CounterParty, fmt.Sprintf("%.2f", transaction.Amount)})
+	}
+	return fmt.Sprintf("%s\n%d un-categorized transactions from %s", t.Render(), len(group.transactions), group.label)
+}
+
+// promptCategoryRule offers to save a rule that sets the category of the payee's future
+// transactions, and returns the ID of the rule, or 0 if none was saved.
+func promptCategoryRule(ctx context.Context, queries *models.Queries, group payeeGroup, categoryId int64) (int64, error) {
+	savePrompt := promptui.Prompt{Label: fmt.Sprintf("Save a rule for future transactions from %s", group.label), IsConfirm: true}
+	if _, err := savePrompt.Run(); err != nil {
+		if errors.Is(err, promptui.ErrAbort) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("prompt failed %w", err)
+	}
+	patternPrompt := promptui.Prompt{
+		Label:     "Payee regular expression",
+		Default:   counterPartyPattern(group.transactions[0].CounterParty),
+		AllowEdit: true,
+		Validate: func(s string) error {
+			if s == "" {
+				return errors.New("regular expression can't be empty")
+			}
+			if _, err := regexp.Compile(s); err != nil {
+				return fmt.Errorf("invalid regular expression: %w", err)
+			}
+			return nil
+		},
+	}
+	pattern, err := patternPrompt.Run()
+	if err != nil {
+		return 0, fmt.Errorf("prompt failed %w", err)
+	}
+	rules, err := queries.ReadAllRules(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error reading rules: %w", err)
+	}
+	var priority int64 = 1
+	if len(rules) > 0 {
+		priority = rules[len(rules)-1].Priority + 1
+	}
+	id, err := queries.CreateRule(ctx, models.CreateRuleParams{
+		Priority:     priority,
+		CounterParty: sql.NullString{Valid: true, String: pattern},
+		CategoryID:   sql.NullInt64{Valid: true, Int64: categoryId},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error creating rule: %w", err)
+	}
+	return id, nil
+}
+
+// undoCategorizeChoice un-categorizes the transactions of a choice and deletes its rule.
+func undoCategorizeChoice(ctx context.Context, queries *models.Queries, choice categorizeChoice) error {
+	for _, id := range choice.ids {
+		err := queries.UpdateTransactionCategory(ctx, models.UpdateTransactionCategoryParams{ID: id})
+		if err != nil {
+			return fmt.Errorf("error un-categorizing transaction %d: %w", id, err)
+		}
+	}
+	if choice.ruleId != 0 {
+		if err := queries.DeleteRule(ctx, choice.ruleId); err != nil {
+			return fmt.Errorf("error deleting rule %d: %w", choice.ruleId, err)
+		}
+	}
+	return nil
+}
+
+// requeuePayeeGroup puts a group back at the front of the queue, removing its transactions
+// from the groups they were queued in since.
+func requeuePayeeGroup(queue []payeeGroup, group payeeGroup) []payeeGroup {
+	ids := make(map[int64]bool)
+	for _, transaction := range group.transactions {
+		ids[transaction.TransactionID] = true
+	}
+	requeued := []payeeGroup{group}
+	for _, queued := range queue {
+		var transactions []models.TransactionsView
+		for _, transaction := range queued.transactions {
+			if !ids[transaction.TransactionID] {
+				transactions = append(transactions, transaction)
+			}
+		}
+		if len(transactions) > 0 {
+			queued.transactions = transactions
+			requeued = append(requeued, queued)
+		}
+	}
+	return requeued
+}
+
+// bulkCategorize sets the category, by name or ID, of the transactions with the IDs that
+// match the filter, and prints them.
+func bulkCategorize(ctx context.Context, db *sql.DB, category string, where string, ids []int64, yes bool) error {
+	tree, err := readCategoryTree(ctx, models.New(db))
+	if err != nil {
+		return err
+	}
+	categoryId, err := tree.categoryId(category)
+	if err != nil {
+		return err
+	}
+	q := &transactionQuery{}
+	if where != "" {
+		f, err := parseFilter(where)
+		if err != nil {
+			return err
+		}
+		q.where(f.where, f.args...)
+	}
+	if len(ids) > 0 {
+		var values []any
+		for _, id := range ids {
+			values = append(values, id)
+		}
+		q.whereIn("transaction_id", values)
+	}
+	transactions, err := readTransactions(ctx, db, q)
+	if err != nil {
+		return err
+	}
+	if len(transactions) == 0 {
+		fmt.Println("no transactions match")
+		return nil
+	}
+	if err := renderTransactionTable(transactions); err != nil {
+		return err
+	}
+	if len(transactions) > bulkCategorizeLimit && !yes {
+		return fmt.Errorf("%d transactions match. Pass --yes to categorize more than %d at once", len(transactions), bulkCategorizeLimit)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("error beginning db transaction: %w", err)
+	}
+	defer tx.Rollback()
+	queries := models.New(tx)
+	for _, transaction := range transactions {
+		err := queries.UpdateTransactionCategory(ctx, models.UpdateTransactionCategoryParams{
+			CategoryID:          sql.NullInt64{Valid: true, Int64: categoryId},
+			CategorizedManually: 1,
+			ID:                  transaction.TransactionID})
+		if err != nil {
+			return fmt.Errorf("error setting category: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing categories: %w", err)
+	}
+	fmt.Printf("categorized %d transactions as %s\n", len(transactions), tree.names[categoryId])
+	return nil
+}
+
+// autoCategorize sets the top suggested category of every un-categorized transaction
+// the classifier is at least minConfidence sure of, and has seen a word of the payee in.
+// The categories are set in one db transaction, so either all of them are set or none.
+func autoCategorize(ctx context.Context, db *sql.DB, classifier *categoryClassifier, categoryMap map[string]int64, minConfidence float64) error {
+	if classifier.autoCategories() < 2 {
+		return fmt.Errorf("--auto needs at least 2 categories with %d categorized transactions each to learn from. Categorize some transactions without --auto first", minAutoDocuments)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("error beginning db transaction: %w", err)
+	}
+	defer tx.Rollback()
+	queries := models.New(tx)
+	transactions, err := queries.ReadNonCategorizedTransactions(ctx)
+	if err != nil {
+		return fmt.Errorf("error reading non categorized transactions: %w", err)
+	}
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"ID", "Date", "Account", "Payee", "Amount", "Category", "Confidence"})
+	categorized := 0
+	for _, transaction := range transactions {
+		suggestions := classifier.suggest(transaction, 1)
+		if len(suggestions) == 0 || suggestions[0].Confidence < minConfidence || !classifier.canAutoAssign(transaction, suggestions[0].Category) {
+			continue
+		}
+		categoryId, ok := categoryMap[suggestions[0].Category]
+		if !ok {
+			continue
+		}
+		err := queries.UpdateTransactionCategory(ctx, models.UpdateTransactionCategoryParams{
+			CategoryID: sql.NullInt64{Valid: true, Int64: categoryId},
+			ID:         transaction.TransactionID})
+		if err != nil {
+			return fmt.Errorf("error setting category: %w", err)
+		}
+		t.AppendRow(table.Row{transaction.TransactionID, transaction.Date, accountKeyToName(transaction.AccountName),
+			transaction.CounterParty, fmt.Sprintf("%.2f", transaction.Amount), suggestions[0].Category,
+			fmt.Sprintf("%.0f%%", suggestions[0].Confidence*100)})
+		categorized++
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing categories: %w", err)
+	}
+	if categorized > 0 {
+		t.Render()
+	}
+	fmt.Printf("categorized %d of %d un-categorized transactions\n", categorized, len(transactions))
+	return nil
 }
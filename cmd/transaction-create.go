@@ -8,7 +8,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io/fs"
 
+	"github.com/kahunacohen/trackit/internal/config"
 	"github.com/kahunacohen/trackit/internal/models"
 	"github.com/spf13/cobra"
 )
@@ -26,17 +28,24 @@ one of your CSV files. For example, say somebody gives you cash as a gift.`,
 		categoryId, _ := flags.GetInt64("category-id")
 		counterParty, _ := flags.GetString("counter-party")
 		date, _ := flags.GetString("date")
-		// description, _ := flags.GetString("description")
+		description, _ := flags.GetString("description")
 		ignore, _ := flags.GetBool("ignore")
 		if amount != 0 && counterParty != "" && date != "" {
 			ctx := context.Background()
 			if !validateDateWithDayFormat(date) {
 				return fmt.Errorf("date '%s' is invalid. Must be in form: YYYY/mm/dd", date)
 			}
-			_, _, dbPath, err := getDataPaths()
+			_, configPath, dbPath, err := getDataPaths()
 			if err != nil {
 				return err
 			}
+			conf, err := config.ParseConfig(configPath)
+			if err != nil {
+				if !errors.Is(err, fs.ErrNotExist) {
+					return err
+				}
+				conf = nil
+			}
 			db, err := getDB(dbPath)
 			if err != nil {
 				return err
@@ -52,14 +61,48 @@ one of your CSV files. For example, say somebody gives you cash as a gift.`,
 			} else {
 				accountIdNullInt64 = sql.NullInt64{Valid: false}
 			}
-			err = queries.CreateTransaction(ctx, models.CreateTransactionParams{
+			// Rules fill in the category and ignore flag unless they were passed explicitly.
+			if _, err := createConfigCategories(ctx, queries, conf); err != nil {
+				return err
+			}
+			rules, err := newRuleEngine(ctx, queries, conf)
+			if err != nil {
+				return err
+			}
+			actions := rules.apply(ruleTransaction{
+				AccountName:  account,
+				Amount:       amount,
+				CounterParty: counterParty,
+				Date:         date,
+				Description:  description,
+			})
+			var categorizedManually int64
+			if categoryId != 0 {
+				categorizedManually = 1
+			} else if actions.Category != nil {
+				categoryId, err = queries.ReadCategoryIdByName(ctx, *actions.Category)
+				if err != nil {
+					return fmt.Errorf("error getting category ID for %s: %w", *actions.Category, err)
+				}
+			}
+			if !flags.Changed("ignore") && actions.Ignore != nil {
+				ignore = *actions.Ignore
+			}
+			payees, err := newPayeeResolver(ctx, queries)
+			if err != nil {
+				return err
+			}
+			transactionId, err := queries.CreateTransaction(ctx, models.CreateTransactionParams{
 				AccountID: accountIdNullInt64,
 				Amount:    amount,
 				CategoryID: func() sql.NullInt64 {
 					return sql.NullInt64{Valid: categoryId != 0, Int64: categoryId}
 				}(),
-				CounterParty: counterParty,
-				Date:         date,
+				CategorizedManually: categorizedManually,
+				CounterParty:        counterParty,
+				PayeeID:             payees.resolve(counterParty),
+				Description:         sql.NullString{Valid: description != "", String: description},
+				Date:                date,
 				IgnoreWhenSumming: func() int64 {
 					if ignore {
 						return 1
@@ -71,6 +114,9 @@ one of your CSV files. For example, say somebody gives you cash as a gift.`,
 			if err != nil {
 				return fmt.Errorf("error creating transaction: %w", err)
 			}
+			if err := addTransactionTags(ctx, queries, transactionId, actions.Tags); err != nil {
+				return err
+			}
 
 		} else {
 			return errors.New("must pass at least amount, counter-payer and date flags")
@@ -5,6 +5,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -15,11 +16,20 @@ import (
 var transactionDeleteCmd = &cobra.Command{
 	Use:     "delete",
 	Aliases: []string{"rm"},
-	Args:    cobra.ExactArgs(1),
 	Short:   "deletes a transaction",
-	Long:    `deletes a transaction by ID. trackit transaction delete <id>`,
+	Long: `deletes transactions by ID. trackit transaction delete <id> [<id>...]
+
+To delete the transactions matching the flags of trackit transaction list instead, pass them
+with --yes. Without --yes, the transactions that would be deleted are only listed. E.g.:
+
+$ trackit transaction delete --account visa --date 2026-03 --yes`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		_, _, dbPath, err := getDataPaths()
+		yes, _ := cmd.Flags().GetBool("yes")
+		filtered := hasTransactionFilters(cmd.Flags())
+		if len(args) == 0 && !filtered {
+			return errors.New("pass the IDs of the transactions to delete, or flags to select them")
+		}
+		_, configPath, dbPath, err := getDataPaths()
 		if err != nil {
 			return err
 		}
@@ -27,13 +37,56 @@ var transactionDeleteCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		queries := models.New(db)
-		transactionID, err := strconv.Atoi(args[0])
+		defer db.Close()
+		ctx := context.Background()
+		q, err := transactionQueryFromFlags(ctx, models.New(db), cmd.Flags(), configPath)
+		if err != nil {
+			return err
+		}
+		if len(args) > 0 {
+			var ids []any
+			for _, arg := range args {
+				id, err := strconv.ParseInt(arg, 10, 64)
+				if err != nil {
+					return fmt.Errorf("error converting id to int: %w", err)
+				}
+				ids = append(ids, id)
+			}
+			q.whereIn("transaction_id", ids)
+		}
+		transactions, err := readTransactions(ctx, db, q)
+		if err != nil {
+			return err
+		}
+		if len(transactions) == 0 {
+			fmt.Println("no transactions match")
+			return nil
+		}
+		if filtered {
+			if err := renderTransactionTable(transactions); err != nil {
+				return err
+			}
+			if !yes {
+				fmt.Printf("pass --yes to delete these %d transactions\n", len(transactions))
+				return nil
+			}
+		}
+		tx, err := db.Begin()
 		if err != nil {
-			return fmt.Errorf("error converting id to int: %w", err)
+			return fmt.Errorf("error beginning db transaction: %w", err)
+		}
+		defer tx.Rollback()
+		queries := models.New(tx)
+		for _, transaction := range transactions {
+			if err = queries.DeleteTransaction(ctx, transaction.TransactionID); err != nil {
+				return fmt.Errorf("error deleting transaction: %w", err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("error committing deletion: %w", err)
 		}
-		if err = queries.DeleteTransaction(context.Background(), int64(transactionID)); err != nil {
-			return fmt.Errorf("error deleting transaction: %w", err)
+		if filtered {
+			fmt.Printf("deleted %d transactions\n", len(transactions))
 		}
 		return nil
 	},
@@ -41,4 +94,6 @@ var transactionDeleteCmd = &cobra.Command{
 
 func init() {
 	transactionCmd.AddCommand(transactionDeleteCmd)
+	addTransactionFilterFlags(transactionDeleteCmd)
+	transactionDeleteCmd.Flags().BoolP("yes", "y", false, "Delete the transactions matching the flags, instead of only listing them")
 }
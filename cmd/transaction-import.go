@@ -8,6 +8,7 @@ import (
 	"crypto/sha256"
 	"database/sql"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -15,7 +16,6 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -69,7 +69,21 @@ var exchangeRateCache = make(map[rateCacheKey]float64)
 
 func processFiles(conf *config.Config, db *sql.DB) error {
 	ctx := context.Background()
-	accountsToColIndices := conf.AccountColumnIndices()
+	created, err := createConfigCategories(ctx, models.New(db), conf)
+	if err != nil {
+		return err
+	}
+	for _, path := range created {
+		logF(verbose, "created category %s from trackit.yaml", path)
+	}
+	rules, err := newRuleEngine(ctx, models.New(db), conf)
+	if err != nil {
+		return err
+	}
+	payees, err := newPayeeResolver(ctx, models.New(db))
+	if err != nil {
+		return err
+	}
 	dataPath, _, _, err := getDataPaths()
 	if err != nil {
 		return err
@@ -115,32 +129,58 @@ func processFiles(conf *config.Config, db *sql.DB) error {
 				tx.Rollback()
 				return nil
 			}
-			reader := csv.NewReader(file)
-			records, err := reader.ReadAll()
+			records, lines, err := readCSVRecords(file)
+			file.Close()
 			if err != nil {
 				tx.Rollback()
 				return fmt.Errorf("error reading %s: %w", path, err)
 			}
-			file.Close()
-			if len(records) < 2 {
-				tx.Rollback()
-				return fmt.Errorf("there are less than 2 rows for file: %s", path)
-			}
-			headersInFile := records[0]
 			accountNameFromFilePtr := getAccountNameFromFileName(conf, fileName)
 			if accountNameFromFilePtr == nil {
+				tx.Rollback()
 				return fmt.Errorf("no matching account name in trackit.yaml for file: '%s'", fileName)
 			}
 			accountNameFromFile := *accountNameFromFilePtr
 			accountFromConf := conf.Accounts[accountNameFromFile]
-			dataRows := records[1:]
+			dataRows, dataLines := records, lines
+			if accountFromConf.HeaderRow() {
+				if len(records) < 2 {
+					tx.Rollback()
+					return fmt.Errorf("there are less than 2 rows for file: %s", path)
+				}
+				dataRows, dataLines = records[1:], lines[1:]
+			}
 			if len(dataRows) == 0 {
 				tx.Rollback()
 				return fmt.Errorf("file %s has no records", path)
 			}
-			headersInConfig := conf.Headers(accountNameFromFile)
+			colIndices, err := accountFromConf.ColumnIndices(records[0])
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("header row of file: '%s' does not match account %s: %w: Check trackit.yaml", path, accountNameFromFile, err)
+			}
+			// Every row must have as many columns as the first one (the header row, if there is one),
+			// and enough of them for the configured column positions.
+			expectedColumns := len(records[0])
+			for tableName, index := range colIndices {
+				if index < 0 || index >= expectedColumns {
+					tx.Rollback()
+					return fmt.Errorf("%s of account %s is at column %d, but %s has %d columns: Check trackit.yaml",
+						tableName, accountNameFromFile, index, path, expectedColumns)
+				}
+			}
+			counterPartyBuilder, err := newCounterPartyBuilder(accountFromConf, records[0], colIndices)
+			if err != nil {
+				tx.Rollback()
+				return fmt.Errorf("error building counter party for account %s from file: '%s': %w: Check trackit.yaml", accountNameFromFile, path, err)
+			}
+			for i, row := range dataRows {
+				if len(row) != expectedColumns {
+					tx.Rollback()
+					return fmt.Errorf("%s line %d: expected %d columns but found %d", path, dataLines[i], expectedColumns, len(row))
+				}
+			}
 			dateLayout := accountFromConf.DateLayout
-			colIndices := accountsToColIndices[accountNameFromFile]
 			bankAccountCurrency := accountFromConf.Currency
 
 			// Insert bank account name into db if it doesn't exist. @TODO put a unique constraint
@@ -164,13 +204,7 @@ func processFiles(conf *config.Config, db *sql.DB) error {
 			// 	tx.Rollback()
 			// 	return fmt.Errorf("error creating account name %s in db: %w", accountNameFromFile, err)
 			// }
-			for _, headerInConfig := range headersInConfig {
-				if !slices.Contains(headersInFile, headerInConfig) {
-					tx.Rollback()
-					return fmt.Errorf("header '%s' in file: '%s' is not a valid header for this account: Check trackit.yaml", headerInConfig, path)
-				}
-			}
-			for _, row := range dataRows {
+			for i, row := range dataRows {
 				rowDateStr := row[colIndices["transaction_date"]]
 				date, err := time.Parse(dateLayout, rowDateStr)
 				if err != nil {
@@ -179,12 +213,13 @@ func processFiles(conf *config.Config, db *sql.DB) error {
 				}
 				var amount float64
 				thousandsSeparator := accountFromConf.ThousandsSeparator
+				decimalSeparator := accountFromConf.DecimalSeparator
 				depositIndx, depositIndxExists := colIndices["deposit"]
 				withdrawlIndx, withdrawlIndxExists := colIndices["withdrawl"]
 				amountIndx, amountIndxExists := colIndices["amount"]
 				if amountIndxExists {
 					amountStr := row[amountIndx]
-					parsedAmount, err := parseAmount(amountStr, thousandsSeparator)
+					parsedAmount, err := parseAmount(amountStr, thousandsSeparator, decimalSeparator)
 					if err != nil {
 						tx.Rollback()
 						return fmt.Errorf("error parsing amount: %s: %w", amountStr, err)
@@ -203,7 +238,7 @@ func processFiles(conf *config.Config, db *sql.DB) error {
 					if depositStr == "" {
 						depositStr = "0"
 					}
-					parsedDeposit, err := parseAmount(depositStr, thousandsSeparator)
+					parsedDeposit, err := parseAmount(depositStr, thousandsSeparator, decimalSeparator)
 					if err != nil {
 						tx.Rollback()
 						return fmt.Errorf("error parsing deposit amount %s in %s", depositStr, path)
@@ -212,7 +247,7 @@ func processFiles(conf *config.Config, db *sql.DB) error {
 					if withdrawlStr == "" {
 						withdrawlStr = "0"
 					}
-					parsedWithdrawl, err := parseAmount(withdrawlStr, thousandsSeparator)
+					parsedWithdrawl, err := parseAmount(withdrawlStr, thousandsSeparator, decimalSeparator)
 					if err != nil {
 						tx.Rollback()
 						return fmt.Errorf("error parsing withdrawl amount %s in %s", withdrawlStr, path)
@@ -253,7 +288,11 @@ func processFiles(conf *config.Config, db *sql.DB) error {
 					amount = roundedAmount
 				}
 
-				counterParty := row[colIndices["counter_party"]]
+				counterParty, rawCounterParty := counterPartyBuilder.build(row)
+				var description sql.NullString
+				if rawCounterParty != counterParty {
+					description = sql.NullString{Valid: true, String: rawCounterParty}
+				}
 
 				// Get bank account if it exists, otherwise create it in DB. @TODO create function
 				bankAccountId, err := txQueries.ReadAccountIdByName(ctx, accountNameFromFile)
@@ -283,33 +322,49 @@ func processFiles(conf *config.Config, db *sql.DB) error {
 					tx.Rollback()
 					return fmt.Errorf("error getting bank account ID for %s: %w", accountNameFromFile, err)
 				}
-				categoryName, err := getCategory(conf, counterParty)
-				if err != nil {
-					tx.Rollback()
-					return fmt.Errorf("error getting category: %w", err)
+				if accountFromConf.DebitAsPositive {
+					amount = -amount
 				}
-				var categoryId int64
-				if categoryName != nil {
-					categoryId, err = txQueries.ReadCategoryIdByName(ctx, *categoryName)
+				actions := rules.apply(ruleTransaction{
+					AccountName:  accountNameFromFile,
+					Amount:       amount,
+					CounterParty: counterParty,
+					Date:         date.Format("2006-01-02"),
+					Description:  description.String,
+				})
+				var categoryId *int64
+				if actions.Category != nil {
+					id, err := txQueries.ReadCategoryIdByName(ctx, *actions.Category)
 					if err != nil {
 						tx.Rollback()
-						return fmt.Errorf("error getting category ID: %w", err)
+						return fmt.Errorf("error getting category ID for %s: %w", *actions.Category, err)
 					}
+					categoryId = &id
 				}
-				if accountFromConf.DebitAsPositive {
-					amount = -amount
+				var ignore int64
+				if actions.Ignore != nil && *actions.Ignore {
+					ignore = 1
 				}
 				logF(verbose, "inserting transaction for %f, in account: %s\n", amount, accountNameFromFile)
-				err = txQueries.CreateTransaction(ctx, models.CreateTransactionParams{
-					AccountID:    sql.NullInt64{Valid: true, Int64: bankAccountId},
-					Date:         date.Format("2006-01-02"),
-					Amount:       amount,
-					CounterParty: counterParty,
-					CategoryID:   toNullInt64(&categoryId)})
+				transactionId, err := txQueries.CreateTransaction(ctx, models.CreateTransactionParams{
+					AccountID:         sql.NullInt64{Valid: true, Int64: bankAccountId},
+					Date:              date.Format("2006-01-02"),
+					Amount:            amount,
+					CounterParty:      counterParty,
+					PayeeID:           payees.resolve(counterParty),
+					Description:       description,
+					CategoryID:        toNullInt64(categoryId),
+					IgnoreWhenSumming: ignore,
+					SourceFile:        sql.NullString{Valid: true, String: fileName},
+					SourceLine:        sql.NullInt64{Valid: true, Int64: int64(dataLines[i])}})
 				if err != nil {
 					tx.Rollback()
 					return fmt.Errorf("error inserting transaction: %w", err)
 				}
+				if err := addTransactionTags(ctx, txQueries, transactionId, actions.Tags); err != nil {
+					tx.Rollback()
+					return err
+				}
 			} // end iteration of data rows in file
 			if hashFromDb == "" {
 				logF(verbose, "file %s had never been processed, insert hash to db\n", path)
@@ -339,6 +394,28 @@ func processFiles(conf *config.Config, db *sql.DB) error {
 	return nil
 }
 
+// readCSVRecords reads all records of a CSV file along with the line each record starts on.
+// Records may have differing numbers of fields, so the caller can report which line is off.
+func readCSVRecords(file *os.File) ([][]string, []int, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	var records [][]string
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, record)
+		lines = append(lines, line)
+	}
+	return records, lines, nil
+}
+
 func getAccountNameFromFileName(conf *config.Config, fileName string) *string {
 	for k := range conf.Accounts {
 		if strings.Contains(fileName, k) {
@@ -370,20 +447,72 @@ func computeFileHash(file *os.File) (string, error) {
 	return fmt.Sprintf("%x", hash.Sum(nil)), nil
 }
 
-func getCategory(conf *config.Config, counterParty string) (*string, error) {
-	for categoryName, counterParties := range conf.Categories {
-		for _, regexpStr := range counterParties {
-			// @TODO In efficent to compile so many times...
-			re, err := regexp.Compile(regexpStr)
-			if err != nil {
-				return nil, fmt.Errorf("error getting category: %w", err)
-			}
-			if re.MatchString(counterParty) {
-				return &categoryName, nil
-			}
+var counterPartyPlaceholderRe = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// counterPartyBuilder builds the counter party of a transaction from a row of an account's
+// CSV file, either from the counter_party column or from the account's counter party
+// template, then extracts the clean name with the account's counter party patterns.
+type counterPartyBuilder struct {
+	column   int
+	template string
+	// placeholders maps each placeholder of the template, e.g. "{Payee}", to its column.
+	placeholders map[string]int
+	patterns     []*regexp.Regexp
+}
+
+func newCounterPartyBuilder(account config.Account, headerRow []string, colIndices map[string]int) (*counterPartyBuilder, error) {
+	builder := &counterPartyBuilder{template: account.CounterPartyTemplate, placeholders: make(map[string]int)}
+	if builder.template == "" {
+		column, ok := colIndices["counter_party"]
+		if !ok {
+			return nil, errors.New("must map a column to counter_party or set counter_party_template")
+		}
+		builder.column = column
+	}
+	for _, match := range counterPartyPlaceholderRe.FindAllStringSubmatch(builder.template, -1) {
+		index := account.HeaderIndex(headerRow, match[1])
+		if index < 0 || index >= len(headerRow) {
+			return nil, fmt.Errorf("no column named '%s' in counter_party_template", match[1])
 		}
+		builder.placeholders[match[0]] = index
 	}
-	return nil, nil
+	for _, pattern := range account.CounterPartyPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid counter_party_patterns regular expression '%s': %w", pattern, err)
+		}
+		builder.patterns = append(builder.patterns, re)
+	}
+	return builder, nil
+}
+
+// build returns the counter party and the raw text it was extracted from.
+func (b *counterPartyBuilder) build(row []string) (string, string) {
+	var raw string
+	if b.template == "" {
+		raw = row[b.column]
+	} else {
+		raw = counterPartyPlaceholderRe.ReplaceAllStringFunc(b.template, func(placeholder string) string {
+			return row[b.placeholders[placeholder]]
+		})
+	}
+	raw = strings.Join(strings.Fields(raw), " ")
+	for _, re := range b.patterns {
+		match := re.FindStringSubmatch(raw)
+		if match == nil {
+			continue
+		}
+		extracted := match[0]
+		if i := re.SubexpIndex("payee"); i != -1 {
+			extracted = match[i]
+		} else if len(match) > 1 {
+			extracted = match[1]
+		}
+		if extracted = strings.TrimSpace(extracted); extracted != "" {
+			return extracted, raw
+		}
+	}
+	return raw, raw
 }
 
 type ExchangeRate struct {
@@ -396,13 +525,27 @@ type ExchangeRatesWrapper struct {
 	ExchangeRates []ExchangeRate `json:"exchange_rates"`
 }
 
-func parseAmount(amount string, thousandsSeparator string) (*float64, error) {
+// Currency symbols some banks prefix amounts with, e.g. -$12.00
+const currencySymbols = "$€£₪¥"
+
+// parseAmount parses an amount written with a thousands separator, if not empty, and a
+// decimal separator, which is "." if empty, e.g. 1.234,56 with "." and ",".
+func parseAmount(amount string, thousandsSeparator string, decimalSeparator string) (*float64, error) {
 	var amountStr string
 	if thousandsSeparator != "" {
-		amountStr = strings.Replace(amount, thousandsSeparator, "", 1)
+		amountStr = strings.ReplaceAll(amount, thousandsSeparator, "")
 	} else {
 		amountStr = amount
 	}
+	if decimalSeparator != "" && decimalSeparator != "." {
+		amountStr = strings.Replace(amountStr, decimalSeparator, ".", 1)
+	}
+	amountStr = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(currencySymbols, r) {
+			return -1
+		}
+		return r
+	}, amountStr)
 	ret, err := strconv.ParseFloat(strings.TrimSpace(amountStr), 64)
 	if err != nil {
 		return nil, err
@@ -7,23 +7,41 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"os"
-	"time"
+	"strings"
 
-	"github.com/jedib0t/go-pretty/v6/table"
-	"github.com/kahunacohen/trackit/internal/config"
 	"github.com/kahunacohen/trackit/internal/models"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var transactionListCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "Lists transactions",
-	Long:    `Lists transactions.`,
+	Long: `Lists transactions, filtered by the flags. Flags of different kinds must all match, e.g.:
+
+$ trackit transaction list --from 2026-01-01 --to 2026-03-31 --category Groceries --sign negative --min 100
+$ trackit transaction list --account visa --account checking --uncategorized
+
+For anything else, pass a filter to --where. Filters compare the fields id, date, account, payee,
+counter_party, description, amount, category, kind, ignored and tag with =, !=, <, <=, >, >=,
+~ (contains) and !~ (doesn't contain), or test them with is null and is not null, combined with
+and, or, not and parentheses. Quote values with spaces:
+
+$ trackit transaction list --where 'payee ~ "whole foods" and (amount < -100 or tag = reimbursable)'
+
+Transactions are listed newest first. Sort them by another column with --sort, and page them
+with --limit and --offset, e.g.:
+
+$ trackit transaction list --sort amount --limit 10
+$ trackit transaction list --limit 50 --offset 50
+
+The totals are of all the transactions matching the flags, not just those listed. Long listings
+are paged with $PAGER (less by default) when printed to a terminal, unless you pass --no-pager.
+
+trackit transaction search takes the same flags, and trackit transaction aggregate the same
+filter flags.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		date, _ := cmd.Flags().GetString("date")
-		account, _ := cmd.Flags().GetString("account")
 		_, configPath, dbPath, err := getDataPaths()
 		if err != nil {
 			return err
@@ -32,149 +50,65 @@ var transactionListCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		if date != "" {
-			_, err := time.Parse("2006-01", date)
-			if err != nil {
-				return fmt.Errorf("date must be in YYYY-MM format")
-			}
-		}
-		if account != "" {
-			conf, err := config.ParseConfig(configPath)
-			if err != nil {
-				return fmt.Errorf("error parsing config: %v", err)
-			}
-			_, ok := conf.Accounts[account]
-			if !ok {
-				return fmt.Errorf("invalid account specified: %s. Check your config for valid account keys", account)
-			}
-		}
-		transactions, total, err := getAccountTransactions(db, account, date)
+		ctx := context.Background()
+		q, err := transactionQueryFromFlags(ctx, models.New(db), cmd.Flags(), configPath)
 		if err != nil {
-			return fmt.Errorf("error getting transactions: %w", err)
-		}
-
-		err = renderTransactionTable(transactions, total)
-		if err != nil {
-			return fmt.Errorf("error rendering transactions: %w", err)
+			return err
 		}
-		return nil
+		return listTransactions(ctx, db, q, cmd.Flags())
 	},
 }
 
 func init() {
 	transactionCmd.AddCommand(transactionListCmd)
-	transactionListCmd.Flags().StringP("date", "d", "", "Date in YYYY-MM format. For now, day precision is not implemented.")
-	transactionListCmd.Flags().StringP("account", "a", "", "One of the account names in your trackit config file")
+	addTransactionFilterFlags(transactionListCmd)
+	addTransactionListingFlags(transactionListCmd, "date")
 }
 
-func RenderAggregateTable(aggregates []models.AggregateTransactionsRow) {
-	t := table.NewWriter()
-	t.SetStyle(table.StyleLight)
-	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"Category", "Total"})
-	for _, aggregate := range aggregates {
-		t.AppendRow([]interface{}{aggregate.CategoryName, fmt.Sprintf("%.2f", aggregate.TotalAmount)})
+// listTransactions prints the transactions a query selects, sorted and paged by the
+// listing flags, with the totals of all of them rather than just the page.
+func listTransactions(ctx context.Context, db *sql.DB, q *transactionQuery, flags *pflag.FlagSet) error {
+	if err := sortTransactionQuery(q, flags); err != nil {
+		return err
 	}
-	t.Render()
-}
-
-func getAccountTransactions(db *sql.DB, accountName string, date string) ([]models.TransactionsView, *float64, error) {
-	var transactions []models.TransactionsView
-	var total float64
-	queries := models.New(db)
-	// account and date are not set
-	ctx := context.Background()
-
-	// @TODO this is a bit messy, repeated code, etc. Maybe make a wrapper function
-	// that handles the distinct types but with same fields.
-	if accountName == "" && date == "" {
-		ts, err := queries.ReadTransactionsWithSum(ctx)
-		if err != nil {
-			return nil, nil, err
-		}
-		if len(ts) > 0 {
-			total = ts[0].TotalAmount.Float64
-		}
-		for _, t := range ts {
-			transactions = append(transactions, models.TransactionsView{
-				AccountID:         t.AccountID,
-				AccountName:       t.AccountName,
-				TransactionID:     t.TransactionID,
-				Date:              t.Date,
-				CounterParty:      t.CounterParty,
-				Amount:            t.Amount,
-				IgnoreWhenSumming: t.IgnoreWhenSumming,
-				Description:       t.Description,
-				CategoryName:      t.CategoryName,
-			})
-		}
-	} else if accountName != "" && date != "" {
-		ts, err := queries.ReadTransactionsByAccountNameAndDateWithSum(ctx, models.ReadTransactionsByAccountNameAndDateWithSumParams{
-			AccountName: sql.NullString{Valid: true, String: accountName},
-			Date:        date})
+	transactions, err := readTransactions(ctx, db, q)
+	if err != nil {
+		return fmt.Errorf("error getting transactions: %w", err)
+	}
+	r := transactionReport(transactions)
+	if q.paged() {
+		summary, err := readTransactionSummary(ctx, db, q)
 		if err != nil {
-			return nil, nil, err
-		}
-		if len(ts) > 0 {
-			total = ts[0].TotalAmount.Float64
-		}
-		for _, t := range ts {
-			transactions = append(transactions, models.TransactionsView{
-				AccountID:         t.AccountID,
-				AccountName:       t.AccountName,
-				TransactionID:     t.TransactionID,
-				Date:              t.Date,
-				CounterParty:      t.CounterParty,
-				Amount:            t.Amount,
-				IgnoreWhenSumming: t.IgnoreWhenSumming,
-				Description:       t.Description,
-				CategoryName:      t.CategoryName,
-			})
+			return err
 		}
+		r.summary = summary.lines()
+	}
+	noPager, _ := flags.GetBool("no-pager")
+	return printPagedReport(r, noPager)
+}
 
-		// account name is set but not date
-	} else if accountName != "" && date == "" {
-		ts, err := queries.ReadTransactionsByAccountNameWithSum(ctx, sql.NullString{Valid: true, String: accountName})
-		if err != nil {
-			return nil, nil, err
-		}
-		if len(ts) > 0 {
-			total = ts[0].TotalAmount.Float64
-		}
-		for _, t := range ts {
-			transactions = append(transactions, models.TransactionsView{
-				AccountID:         t.AccountID,
-				AccountName:       t.AccountName,
-				TransactionID:     t.TransactionID,
-				Date:              t.Date,
-				CounterParty:      t.CounterParty,
-				Amount:            t.Amount,
-				IgnoreWhenSumming: t.IgnoreWhenSumming,
-				Description:       t.Description,
-				CategoryName:      t.CategoryName,
-			})
-		}
-	} else {
-		ts, err := queries.ReadTransactionsByDateWithSum(ctx, date)
-		if err != nil {
-			return nil, nil, err
-		}
-		if len(ts) > 0 {
-			total = ts[0].TotalAmount.Float64
-		}
-		for _, t := range ts {
-			transactions = append(transactions, models.TransactionsView{
-				AccountID:         t.AccountID,
-				AccountName:       t.AccountName,
-				TransactionID:     t.TransactionID,
-				Date:              t.Date,
-				CounterParty:      t.CounterParty,
-				Amount:            t.Amount,
-				IgnoreWhenSumming: t.IgnoreWhenSumming,
-				Description:       t.Description,
-				CategoryName:      t.CategoryName,
-			})
+// aggregateReport returns the report of totals by facet. If kinds, the category kind of
+// each facet, is passed, the kinds are included, and if summary is passed, the income,
+// expense and net totals.
+func aggregateReport(facet string, aggregates []models.AggregateTransactionsRow, kinds map[string]string, summary *amountSummary) report {
+	r := report{columns: []outputColumn{{key: strings.ToLower(facet), header: facet}}}
+	if kinds != nil {
+		r.columns = append(r.columns, outputColumn{key: "kind", header: "Kind"})
+	}
+	r.columns = append(r.columns, outputColumn{key: "total", header: "Total"})
+	for _, aggregate := range aggregates {
+		row := []any{aggregate.CategoryName}
+		if kinds != nil {
+			var kind any
+			if k := kinds[aggregate.CategoryName]; k != "" {
+				kind = k
+			}
+			row = append(row, kind)
 		}
+		r.rows = append(r.rows, append(row, outputAmount(aggregate.TotalAmount)))
+	}
+	if summary != nil {
+		r.summary = summary.lines()
 	}
-	return transactions, &total, nil
+	return r
 }
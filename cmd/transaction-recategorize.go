@@ -0,0 +1,169 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/kahunacohen/trackit/internal/config"
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// recategorization is a stored transaction whose category the current rules would change.
+type recategorization struct {
+	transaction models.TransactionsView
+	category    string
+}
+
+var transactionRecategorizeCmd = &cobra.Command{
+	Use:   "recategorize",
+	Short: "Re-applies categorization rules to stored transactions",
+	Long: `Runs the current categorization rules, and the categories in your trackit config file,
+over transactions already in the database and shows the category changes. Transactions
+no rule matches keep their category. Transactions categorized by hand with trackit categorize,
+or created with a category ID, are left alone unless --force is passed. E.g.:
+
+$ trackit transaction recategorize --date 2025-01 --dry-run
+
+The transactions recategorized are selected with the same flags as trackit transaction list.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		onlyUncategorized, _ := flags.GetBool("only-uncategorized")
+		dryRun, _ := flags.GetBool("dry-run")
+		force, _ := flags.GetBool("force")
+		_, configPath, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		conf, err := config.ParseConfig(configPath)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			conf = nil
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		ctx := context.Background()
+		queries := models.New(db)
+		rules, err := newRuleEngine(ctx, queries, conf)
+		if err != nil {
+			return err
+		}
+		q, err := transactionQueryFromFlags(ctx, queries, flags, configPath)
+		if err != nil {
+			return err
+		}
+		if onlyUncategorized {
+			q.whereCategory("category_name IS NULL")
+		}
+		transactions, err := readTransactions(ctx, db, q)
+		if err != nil {
+			return fmt.Errorf("error getting transactions: %w", err)
+		}
+		var changes []recategorization
+		protected := 0
+		for _, transaction := range transactions {
+			actions := rules.apply(ruleTransaction{
+				AccountName:  transaction.AccountName.String,
+				Amount:       transaction.Amount,
+				CounterParty: transaction.CounterParty,
+				Date:         transaction.Date,
+				Description:  transaction.Description.String,
+			})
+			if actions.Category == nil || *actions.Category == transaction.CategoryName.String {
+				continue
+			}
+			if transaction.CategorizedManually == 1 && !force {
+				protected++
+				continue
+			}
+			changes = append(changes, recategorization{transaction: transaction, category: *actions.Category})
+		}
+		if len(changes) > 0 {
+			renderRecategorizationTable(changes)
+		}
+		if protected > 0 {
+			fmt.Printf("skipped %d transactions categorized by hand. Pass --force to recategorize them\n", protected)
+		}
+		if dryRun {
+			fmt.Printf("%d transactions would be recategorized\n", len(changes))
+			return nil
+		}
+		if len(changes) == 0 {
+			fmt.Println("no transactions to recategorize")
+			return nil
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("error beginning db transaction: %w", err)
+		}
+		defer tx.Rollback()
+		txQueries := models.New(tx)
+		categoryIds := make(map[string]int64)
+		for _, change := range changes {
+			categoryId, ok := categoryIds[change.category]
+			if !ok {
+				categoryId, err = txQueries.ReadCategoryIdByName(ctx, change.category)
+				if err != nil {
+					return fmt.Errorf("error getting category ID for %s: %w", change.category, err)
+				}
+				categoryIds[change.category] = categoryId
+			}
+			err = txQueries.UpdateTransactionCategory(ctx, models.UpdateTransactionCategoryParams{
+				CategoryID: sql.NullInt64{Valid: true, Int64: categoryId},
+				ID:         change.transaction.TransactionID})
+			if err != nil {
+				return fmt.Errorf("error updating category of transaction %d: %w", change.transaction.TransactionID, err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("error committing db transaction: %w", err)
+		}
+		fmt.Printf("recategorized %d transactions\n", len(changes))
+		return nil
+	},
+}
+
+func renderRecategorizationTable(changes []recategorization) {
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"ID", "Account", "Date", "Counter Party", "Amount", "Old Category", "New Category"})
+	for _, change := range changes {
+		transaction := change.transaction
+		oldCategory := "Uncategorized"
+		if transaction.CategoryName.Valid {
+			oldCategory = transaction.CategoryName.String
+		}
+		t.AppendRow(table.Row{
+			transaction.TransactionID,
+			transaction.AccountName.String,
+			transaction.Date,
+			transaction.CounterParty,
+			fmt.Sprintf("%.2f", transaction.Amount),
+			oldCategory,
+			change.category})
+	}
+	t.Render()
+}
+
+func init() {
+	transactionCmd.AddCommand(transactionRecategorizeCmd)
+	addTransactionFilterFlags(transactionRecategorizeCmd)
+	transactionRecategorizeCmd.Flags().Bool("only-uncategorized", false, "Only categorize transactions that have no category")
+	transactionRecategorizeCmd.Flags().MarkDeprecated("only-uncategorized", "use --uncategorized instead")
+	transactionRecategorizeCmd.Flags().Bool("dry-run", false, "Show the changes without applying them")
+	transactionRecategorizeCmd.Flags().BoolP("force", "f", false, "Also recategorize transactions that were categorized by hand")
+}
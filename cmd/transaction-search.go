@@ -6,23 +6,52 @@ package cmd
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"time"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
 
-	"github.com/kahunacohen/trackit/internal/config"
 	"github.com/kahunacohen/trackit/internal/models"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var transactionSearchCmd = &cobra.Command{
 	Use:   "search",
-	Short: "Searches transactions' counter_party field for text. trackit search <text>",
-	Long: `Searches the transaction counter payer for text. Can filter by account and date. E.g.
-trackit search <text>`,
-	Args: cobra.ExactArgs(1),
+	Short: "Searches transactions for words. trackit search <query>",
+	Long: `Searches the transaction counter party, payee, description, category and split memos for
+words, listing the most relevant transactions first. Words match whole words, ignoring case and
+accents, and all the words must match. E.g.:
+
+$ trackit transaction search whole foods
+$ trackit transaction search '"whole foods" OR shufersal'
+$ trackit transaction search 'amaz* NOT prime'
+$ trackit transaction search payee:amazon desc:books amount:>100
+
+Quote phrases with double quotes, end a word with * to match words starting with it, combine words
+with AND, OR, NOT (in capitals) and parentheses, and prefix a word with payee:, counter_party:,
+desc:, category: or notes: to only search that field. account:<key> only matches the transactions of
+an account, and amount:>100, amount:<50 or amount:25.50 those with an absolute amount of at least,
+at most or exactly that.
+
+With --regex, the terms are Go regular expressions, matched like the conditions of rules: a term,
+or payee: term, must match part of the counter party, a desc: term the description, and a category:
+term the category name. Pass --save-rule <category> to save the search as a rule that sets the
+category, e.g.:
+
+$ trackit transaction search --regex '^PAYPAL \*(SPOTIFY|NETFLIX)' --save-rule Subscriptions
+
+Can filter, sort and page with the same flags as trackit transaction list, e.g. --sort date for the
+newest first.`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		date, _ := cmd.Flags().GetString("date")
-		account, _ := cmd.Flags().GetString("account")
+		regex, _ := cmd.Flags().GetBool("regex")
+		saveRule, _ := cmd.Flags().GetString("save-rule")
+		if saveRule != "" && !regex {
+			return errors.New("--save-rule needs --regex, as rules match regular expressions")
+		}
 		_, configPath, dbPath, err := getDataPaths()
 		if err != nil {
 			return err
@@ -31,115 +60,322 @@ trackit search <text>`,
 		if err != nil {
 			return err
 		}
+		ctx := context.Background()
 		queries := models.New(db)
-		if date != "" {
-			if date != "" {
-				_, err := time.Parse("2006-01", date)
-				if err != nil {
-					return fmt.Errorf("date must be in YYYY-MM format")
-				}
+		q, err := transactionQueryFromFlags(ctx, queries, cmd.Flags(), configPath)
+		if err != nil {
+			return err
+		}
+		s, err := parseSearch(ctx, queries, args, regex)
+		if err != nil {
+			return err
+		}
+		var rule models.Rule
+		if saveRule != "" {
+			if rule, err = searchRule(ctx, queries, s, cmd.Flags(), saveRule); err != nil {
+				return err
 			}
 		}
-		if account != "" {
-			conf, err := config.ParseConfig(configPath)
-			if err != nil {
-				return fmt.Errorf("error parsing config: %v", err)
+		if len(s.words) > 0 {
+			q.whereMatch(ftsQuery(strings.Join(s.words, " ")))
+		} else if !cmd.Flags().Changed("sort") {
+			cmd.Flags().Set("sort", "date")
+		}
+		q.whereRule(s.rule)
+		if s.category != "" {
+			q.whereCategory("category_name REGEXP ?", s.category)
+		}
+		if err := listTransactions(ctx, db, q, cmd.Flags()); err != nil {
+			return err
+		}
+		if saveRule == "" {
+			return nil
+		}
+		id, err := queries.CreateRule(ctx, models.CreateRuleParams{
+			Priority:     rule.Priority,
+			CounterParty: rule.CounterParty,
+			Description:  rule.Description,
+			AccountID:    rule.AccountID,
+			MinAmount:    rule.MinAmount,
+			MaxAmount:    rule.MaxAmount,
+			Sign:         rule.Sign,
+			FromDate:     rule.FromDate,
+			ToDate:       rule.ToDate,
+			CategoryID:   rule.CategoryID,
+		})
+		if err != nil {
+			return fmt.Errorf("error creating rule: %w", err)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "saved rule %d. Run trackit transaction recategorize to apply it to these transactions\n", id)
+		return nil
+	},
+}
+
+func init() {
+	transactionCmd.AddCommand(transactionSearchCmd)
+	addTransactionFilterFlags(transactionSearchCmd)
+	addTransactionListingFlags(transactionSearchCmd, "relevance")
+	transactionSearchCmd.Flags().BoolP("regex", "r", false, "Search with regular expressions, matched like the conditions of rules")
+	transactionSearchCmd.Flags().String("save-rule", "", "Save the --regex search as a rule that sets this category")
+}
+
+// search is a parsed search: full-text words, or the conditions of a rule matching the
+// same transactions. A category regular expression is kept apart, as rules can't match
+// on the category.
+type search struct {
+	words    []string
+	rule     models.Rule
+	category string
+}
+
+// parseSearch parses the terms of a search. account: and amount: terms are conditions of
+// the rule in both modes. Other terms are full-text words, or regular expressions with
+// regex, which are matched whole, as they may have spaces.
+func parseSearch(ctx context.Context, queries *models.Queries, args []string, regex bool) (*search, error) {
+	s := &search{}
+	terms := args
+	if !regex {
+		terms = searchWords(strings.Join(args, " "))
+	}
+	for _, term := range terms {
+		field, value, found := strings.Cut(term, ":")
+		if !found {
+			field, value = "", term
+		}
+		switch {
+		case field == "account":
+			id, err := queries.ReadAccountIdByName(ctx, value)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("invalid account specified: %s. Check your config for valid account keys", value)
+			} else if err != nil {
+				return nil, fmt.Errorf("error getting account ID for %s: %w", value, err)
+			}
+			s.rule.AccountID = sql.NullInt64{Valid: true, Int64: id}
+		case field == "amount":
+			if err := setAmountTerm(&s.rule, value); err != nil {
+				return nil, err
+			}
+		case !regex:
+			s.words = append(s.words, term)
+		case field == "desc" || field == "description":
+			if err := setRegexTerm(&s.rule.Description, "desc", value); err != nil {
+				return nil, err
 			}
-			_, ok := conf.Accounts[account]
-			if !ok {
-				return fmt.Errorf("invalid account specified: %s. Check your config for valid account keys", account)
+		case field == "category":
+			if _, err := regexp.Compile(value); err != nil {
+				return nil, fmt.Errorf("invalid regular expression '%s': %w", value, err)
+			}
+			s.category = value
+		case field == "payee" || field == "counter_party":
+			if err := setRegexTerm(&s.rule.CounterParty, "payee", value); err != nil {
+				return nil, err
+			}
+		default:
+			if err := setRegexTerm(&s.rule.CounterParty, "payee", term); err != nil {
+				return nil, err
 			}
 		}
-		var transactions []models.TransactionsView
-		var total float64
+	}
+	return s, nil
+}
 
-		if date == "" && account == "" {
-			ts, err := queries.SearchTransactionsWithSum(context.Background(), sql.NullString{Valid: true, String: args[0]})
+// setRegexTerm sets a regular expression condition of a rule, of which there is one per
+// field.
+func setRegexTerm(condition *sql.NullString, field string, value string) error {
+	if condition.Valid {
+		return fmt.Errorf("only one %s regular expression can be searched for, as a rule only has one", field)
+	}
+	if _, err := regexp.Compile(value); err != nil {
+		return fmt.Errorf("invalid regular expression '%s': %w", value, err)
+	}
+	*condition = sql.NullString{Valid: true, String: value}
+	return nil
+}
 
-			if len(ts) > 0 {
-				total = ts[0].TotalAmount.Float64
-			}
+// setAmountTerm sets the amount range of a rule from an amount: term, e.g. >100. Like the
+// --min and --max of rules, it bounds the absolute amount, inclusively.
+func setAmountTerm(rule *models.Rule, term string) error {
+	operator := ""
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(term, op) {
+			operator = op
+			break
+		}
+	}
+	f, err := strconv.ParseFloat(strings.TrimPrefix(term, operator), 64)
+	if err != nil {
+		return fmt.Errorf("amount:%s is invalid. Must be a number, optionally after >, >=, <, <= or =", term)
+	}
+	amount := sql.NullFloat64{Valid: true, Float64: math.Abs(f)}
+	if operator != "<" && operator != "<=" {
+		rule.MinAmount = amount
+	}
+	if operator != ">" && operator != ">=" {
+		rule.MaxAmount = amount
+	}
+	return nil
+}
+
+// searchRule returns the rule that a regular expression search is saved as. It has the
+// conditions of the search terms, and of the filter flags rules have conditions for, and
+// sets the category.
+func searchRule(ctx context.Context, queries *models.Queries, s *search, flags *pflag.FlagSet, category string) (models.Rule, error) {
+	rule := s.rule
+	if s.category != "" {
+		return rule, errors.New("a search for a category: can't be saved as a rule, as rules can't match on the category")
+	}
+	for _, name := range transactionFilterFlags {
+		if !flags.Changed(name) {
+			continue
+		}
+		switch name {
+		case "from":
+			from, _ := flags.GetString("from")
+			rule.FromDate = sql.NullString{Valid: true, String: from}
+		case "to":
+			to, _ := flags.GetString("to")
+			rule.ToDate = sql.NullString{Valid: true, String: to}
+		case "sign":
+			sign, _ := flags.GetString("sign")
+			rule.Sign = sql.NullString{Valid: true, String: sign}
+		case "min", "max":
+			value, _ := flags.GetString(name)
+			f, err := strconv.ParseFloat(value, 64)
 			if err != nil {
-				return fmt.Errorf("error searching transactions: %w", err)
+				return rule, fmt.Errorf("--%s must be a number: %w", name, err)
 			}
-			for _, t := range ts {
-				transactions = append(transactions, models.TransactionsView{
-					AccountID:         t.AccountID,
-					AccountName:       t.AccountName,
-					TransactionID:     t.TransactionID,
-					Date:              t.Date,
-					CounterParty:      t.CounterParty,
-					Amount:            t.Amount,
-					IgnoreWhenSumming: t.IgnoreWhenSumming,
-					Description:       t.Description,
-					CategoryName:      t.CategoryName,
-				})
+			if name == "min" {
+				rule.MinAmount = sql.NullFloat64{Valid: true, Float64: f}
+			} else {
+				rule.MaxAmount = sql.NullFloat64{Valid: true, Float64: f}
 			}
-		} else if date != "" && account == "" {
-			ts, err := queries.SearchTransactionsByDateWithSum(context.Background(), models.SearchTransactionsByDateWithSumParams{
-				SearchTerm: sql.NullString{Valid: true, String: args[0]},
-				Date:       date,
-			})
-
-			if len(ts) > 0 {
-				total = ts[0].TotalAmount.Float64
+		case "account":
+			accounts, _ := flags.GetStringSlice("account")
+			if len(accounts) > 1 || rule.AccountID.Valid {
+				return rule, errors.New("a search of more than one account can't be saved as a rule")
 			}
+			id, err := queries.ReadAccountIdByName(ctx, accounts[0])
 			if err != nil {
-				return fmt.Errorf("error searching transactions: %w", err)
+				return rule, fmt.Errorf("error getting account ID for %s: %w", accounts[0], err)
 			}
-			for _, t := range ts {
-				transactions = append(transactions, models.TransactionsView{
-					AccountID:         t.AccountID,
-					AccountName:       t.AccountName,
-					TransactionID:     t.TransactionID,
-					Date:              t.Date,
-					CounterParty:      t.CounterParty,
-					Amount:            t.Amount,
-					IgnoreWhenSumming: t.IgnoreWhenSumming,
-					Description:       t.Description,
-					CategoryName:      t.CategoryName,
-				})
+			rule.AccountID = sql.NullInt64{Valid: true, Int64: id}
+		default:
+			return rule, fmt.Errorf("a search with --%s can't be saved as a rule, as rules have no such condition", name)
+		}
+	}
+	tree, err := readCategoryTree(ctx, queries)
+	if err != nil {
+		return rule, err
+	}
+	categoryId, err := tree.categoryId(category)
+	if err != nil {
+		return rule, err
+	}
+	rule.CategoryID = sql.NullInt64{Valid: true, Int64: categoryId}
+	rules, err := queries.ReadAllRules(ctx)
+	if err != nil {
+		return rule, fmt.Errorf("error reading rules: %w", err)
+	}
+	rule.Priority = 1
+	if len(rules) > 0 {
+		rule.Priority = rules[len(rules)-1].Priority + 1
+	}
+	return rule, validateRule(rule)
+}
+
+// searchWords splits a search into words at spaces outside of double quotes.
+func searchWords(search string) []string {
+	var words []string
+	var word strings.Builder
+	quoted := false
+	for _, r := range search {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if (r == ' ' || r == '\t') && !quoted {
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
 			}
-		} else {
-			if date != "" {
-				_, err := time.Parse("2006-01", date)
-				if err != nil {
-					return fmt.Errorf("date must be in YYYY-MM format")
+			continue
+		}
+		word.WriteRune(r)
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// ftsColumns are the columns of transactions_fts words can be prefixed with, by prefix.
+// payee searches the counter party too, which the payee is resolved from.
+var ftsColumns = map[string]string{
+	"payee":         "{counter_party payee}",
+	"counter_party": "counter_party",
+	"desc":          "description",
+	"description":   "description",
+	"category":      "category",
+	"notes":         "notes",
+}
+var ftsWordPattern = regexp.MustCompile(`^[\p{L}\p{N}_]+\*?$`)
+
+// ftsQuery returns a search as an FTS5 query. Words FTS5 can't parse, like 7-eleven or
+// AMZN*2K4, are quoted, so they match as phrases of the words in them.
+func ftsQuery(search string) string {
+	var query strings.Builder
+	for i := 0; i < len(search); {
+		switch c := search[i]; {
+		case c == '"':
+			end := i + 1
+			for end < len(search) {
+				if search[end] == '"' {
+					if end+1 < len(search) && search[end+1] == '"' {
+						end += 2
+						continue
+					}
+					break
 				}
+				end++
 			}
-			ts, err := queries.SearchTransactionsByAccountNameAndDateWithSum(context.Background(), models.SearchTransactionsByAccountNameAndDateWithSumParams{
-				SearchTerm:  sql.NullString{Valid: true, String: args[0]},
-				AccountName: sql.NullString{Valid: true, String: account},
-				Date:        date,
-			})
-
-			if len(ts) > 0 {
-				total = ts[0].TotalAmount.Float64
+			if end == len(search) {
+				// Close a phrase left open.
+				query.WriteString(search[i:] + `"`)
+			} else {
+				query.WriteString(search[i : end+1])
 			}
-			if err != nil {
-				return fmt.Errorf("error searching transactions: %w", err)
-			}
-			for _, t := range ts {
-				transactions = append(transactions, models.TransactionsView{
-					AccountID:         t.AccountID,
-					AccountName:       t.AccountName,
-					TransactionID:     t.TransactionID,
-					Date:              t.Date,
-					CounterParty:      t.CounterParty,
-					Amount:            t.Amount,
-					IgnoreWhenSumming: t.IgnoreWhenSumming,
-					Description:       t.Description,
-					CategoryName:      t.CategoryName,
-				})
+			i = end + 1
+		case c == '(' || c == ')' || c == ' ' || c == '\t' || c == '*':
+			query.WriteByte(c)
+			i++
+		default:
+			end := i
+			for end < len(search) && !strings.ContainsRune("\"() \t", rune(search[end])) {
+				end++
 			}
+			query.WriteString(ftsWord(search[i:end]))
+			i = end
 		}
-		renderTransactionTable(transactions, &total)
-		return nil
-	},
+	}
+	return query.String()
 }
 
-func init() {
-	transactionCmd.AddCommand(transactionSearchCmd)
-	transactionSearchCmd.Flags().StringP("date", "d", "", "Date in YYYY-MM format. For now, day precision is not implemented.")
-	transactionSearchCmd.Flags().StringP("account", "a", "", "One of the account names in your trackit config file")
+func ftsWord(word string) string {
+	if word == "AND" || word == "OR" || word == "NOT" {
+		return word
+	}
+	prefix, rest, found := strings.Cut(word, ":")
+	if column, ok := ftsColumns[prefix]; found && ok {
+		if rest == "" {
+			return column + ":"
+		}
+		return column + ":" + ftsWord(rest)
+	}
+	if ftsWordPattern.MatchString(word) {
+		return word
+	}
+	star := ""
+	if strings.HasSuffix(word, "*") {
+		word, star = strings.TrimSuffix(word, "*"), "*"
+	}
+	return `"` + strings.ReplaceAll(word, `"`, `""`) + `"` + star
 }
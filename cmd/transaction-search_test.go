@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/kahunacohen/trackit/internal/models"
+)
+
+func TestFtsQuery(t *testing.T) {
+	tests := []struct {
+		search string
+		query  string
+	}{
+		{`whole foods`, `whole foods`},
+		{`café`, `café`},
+		{`amaz* NOT prime`, `amaz* NOT prime`},
+		{`(shufersal OR rami) AND levy`, `(shufersal OR rami) AND levy`},
+		{`"whole foods" OR shufersal`, `"whole foods" OR shufersal`},
+		{`"he said ""hi"""`, `"he said ""hi"""`},
+		{`"whole foods`, `"whole foods"`},
+		{`7-eleven`, `"7-eleven"`},
+		{`AMZN*2K4`, `"AMZN*2K4"`},
+		{`o'reilly*`, `"o'reilly"*`},
+		{`payee:amazon`, `{counter_party payee}:amazon`},
+		{`desc:7-eleven`, `description:"7-eleven"`},
+		{`counter_party:"whole foods"`, `counter_party:"whole foods"`},
+		{`notes:`, `notes:`},
+		{`foo:bar`, `"foo:bar"`},
+	}
+	for _, test := range tests {
+		if query := ftsQuery(test.search); query != test.query {
+			t.Errorf("ftsQuery(%q) = %q, want %q", test.search, query, test.query)
+		}
+	}
+}
+
+func TestSearchWords(t *testing.T) {
+	tests := []struct {
+		search string
+		words  []string
+	}{
+		{`whole foods`, []string{"whole", "foods"}},
+		{"  whole\tfoods  ", []string{"whole", "foods"}},
+		{`"whole foods" OR shufersal`, []string{`"whole foods"`, "OR", "shufersal"}},
+		{`payee:"whole foods" amount:>100`, []string{`payee:"whole foods"`, "amount:>100"}},
+		{`"whole foods`, []string{`"whole foods`}},
+		{``, nil},
+	}
+	for _, test := range tests {
+		if words := searchWords(test.search); !reflect.DeepEqual(words, test.words) {
+			t.Errorf("searchWords(%q) = %q, want %q", test.search, words, test.words)
+		}
+	}
+}
+
+func TestSetAmountTerm(t *testing.T) {
+	amount := func(f float64) sql.NullFloat64 { return sql.NullFloat64{Valid: true, Float64: f} }
+	tests := []struct {
+		term string
+		min  sql.NullFloat64
+		max  sql.NullFloat64
+	}{
+		{">100", amount(100), sql.NullFloat64{}},
+		{">=100", amount(100), sql.NullFloat64{}},
+		{"<50", sql.NullFloat64{}, amount(50)},
+		{"<=50", sql.NullFloat64{}, amount(50)},
+		{"<=-50", sql.NullFloat64{}, amount(50)},
+		{"=25.50", amount(25.5), amount(25.5)},
+		{"25.50", amount(25.5), amount(25.5)},
+		{"-25", amount(25), amount(25)},
+	}
+	for _, test := range tests {
+		var rule models.Rule
+		if err := setAmountTerm(&rule, test.term); err != nil {
+			t.Errorf("setAmountTerm(%q) returned error: %v", test.term, err)
+			continue
+		}
+		if rule.MinAmount != test.min || rule.MaxAmount != test.max {
+			t.Errorf("setAmountTerm(%q) set min %v and max %v, want %v and %v", test.term, rule.MinAmount, rule.MaxAmount,
+				test.min, test.max)
+		}
+	}
+	for _, term := range []string{"", ">", "<=abc", "100usd", "=>5"} {
+		var rule models.Rule
+		if err := setAmountTerm(&rule, term); err == nil {
+			t.Errorf("setAmountTerm(%q) returned no error", term)
+		}
+	}
+}
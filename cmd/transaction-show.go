@@ -0,0 +1,145 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var transactionShowCmd = &cobra.Command{
+	Use:   "show",
+	Args:  cobra.ExactArgs(1),
+	Short: "Shows a transaction. trackit transaction show <id>",
+	Long: `Shows all the fields of a transaction, the CSV file and line it was imported from, its tags and
+splits, and the history of changes made to it, e.g. by trackit transaction update or by categorizing it.
+Transactions created with trackit transaction create, or imported by an older trackit, have no file.
+With --output other than table or markdown, e.g. json, the splits and history are lists in the transaction.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		transactionId, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("error parsing transaction id: %w", err)
+		}
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		ctx := context.Background()
+		queries := models.New(db)
+		transaction, err := readTransaction(ctx, queries, transactionId)
+		if err != nil {
+			return err
+		}
+		raw, err := queries.ReadRawTransactionById(ctx, transactionId)
+		if err != nil {
+			return fmt.Errorf("error getting transaction %d: %w", transactionId, err)
+		}
+		tags, err := queries.ReadTransactionTags(ctx, transactionId)
+		if err != nil {
+			return fmt.Errorf("error getting tags: %w", err)
+		}
+		splits, err := queries.ReadTransactionSplits(ctx, transactionId)
+		if err != nil {
+			return fmt.Errorf("error getting splits: %w", err)
+		}
+		history, err := queries.ReadTransactionHistory(ctx, transactionId)
+		if err != nil {
+			return fmt.Errorf("error getting history: %w", err)
+		}
+		var source, line any
+		if raw.SourceFile.Valid {
+			source = fmt.Sprintf("%s, line %d", raw.SourceFile.String, raw.SourceLine.Int64)
+			line = raw.SourceLine.Int64
+		}
+		splitReport := report{title: "Splits", columns: []outputColumn{
+			{key: "category", header: "Category"},
+			{key: "amount", header: "Amount", align: text.AlignRight},
+			{key: "memo", header: "Memo"},
+		}}
+		for _, split := range splits {
+			splitReport.rows = append(splitReport.rows, []any{nullValue(split.CategoryName), outputAmount(split.Amount),
+				nullValue(split.Memo)})
+		}
+		historyReport := report{title: "History", columns: []outputColumn{
+			{key: "changed_at", header: "Changed"},
+			{key: "field", header: "Field"},
+			{key: "from", header: "From"},
+			{key: "to", header: "To"},
+		}}
+		for _, change := range history {
+			historyReport.rows = append(historyReport.rows, []any{change.ChangedAt, change.Field, nullValue(change.OldValue),
+				nullValue(change.NewValue)})
+		}
+		r := report{vertical: true, columns: []outputColumn{
+			{key: "id", header: "ID"},
+			{key: "date", header: "Date"},
+			{key: "amount", header: "Amount"},
+			{key: "payee", header: "Payee"},
+			{key: "counter_party", header: "Counter party"},
+			{key: "description", header: "Description"},
+			{header: "Account"},
+			{key: "account"},
+			{key: "category", header: "Category"},
+			{key: "kind", header: "Kind"},
+			{key: "categorized_manually", header: "Categorized manually"},
+			{key: "ignored", header: "Ignored"},
+			{header: "File"},
+			{key: "file"},
+			{key: "line"},
+			{key: "tags", header: "Tags"},
+			{key: "splits"},
+			{key: "history"},
+		}}
+		r.rows = append(r.rows, []any{transaction.TransactionID, transaction.Date, outputAmount(transaction.Amount),
+			payeeName(transaction), transaction.CounterParty, nullValue(transaction.Description),
+			accountKeyToName(transaction.AccountName), nullValue(transaction.AccountName), nullValue(transaction.CategoryName),
+			nullValue(transaction.CategoryKind), transaction.CategorizedManually == 1, transaction.IgnoreWhenSumming == 1,
+			source, nullValue(raw.SourceFile), line, tags, splitReport, historyReport})
+		format, _ := rootCmd.PersistentFlags().GetString("output")
+		if err := r.write(os.Stdout, format); err != nil {
+			return err
+		}
+		// Tables show the splits and history below the transaction, instead of in it.
+		if format != "table" && format != "markdown" {
+			return nil
+		}
+		for _, rows := range []report{splitReport, historyReport} {
+			if len(rows.rows) == 0 {
+				continue
+			}
+			if err := rows.write(os.Stdout, format); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	transactionCmd.AddCommand(transactionShowCmd)
+}
+
+// readTransaction reads a transaction by ID, with an error saying so if it doesn't exist.
+func readTransaction(ctx context.Context, queries *models.Queries, transactionId int64) (models.TransactionsView, error) {
+	transaction, err := queries.ReadTransactionById(ctx, transactionId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return transaction, fmt.Errorf("no transaction with ID %d", transactionId)
+	} else if err != nil {
+		return transaction, fmt.Errorf("error getting transaction %d: %w", transactionId, err)
+	}
+	return transaction, nil
+}
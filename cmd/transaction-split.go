@@ -0,0 +1,177 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+// transactionSplit is a part of a transaction's amount counted in a category.
+type transactionSplit struct {
+	category string
+	amount   float64
+	memo     string
+}
+
+var transactionSplitCmd = &cobra.Command{
+	Use:   "split",
+	Args:  cobra.ExactArgs(1),
+	Short: "Splits a transaction across categories. trackit transaction split <id>",
+	Long: `Interactively splits a transaction's amount across several categories, e.g. a supermarket
+charge that is partly groceries and partly household supplies. Each split has a category, an
+amount and an optional memo, and the split amounts must add up to the transaction's amount.
+Aggregating by category counts the splits instead of the transaction. Splitting a transaction
+again replaces its splits, and --clear removes them.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clearSplits, _ := cmd.Flags().GetBool("clear")
+		transactionId, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("error parsing transaction id: %w", err)
+		}
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		ctx := context.Background()
+		queries := models.New(db)
+		transaction, err := queries.ReadTransactionById(ctx, transactionId)
+		if err != nil {
+			return fmt.Errorf("error getting transaction %d: %w", transactionId, err)
+		}
+		if clearSplits {
+			if err := queries.DeleteTransactionSplits(ctx, transactionId); err != nil {
+				return fmt.Errorf("error deleting splits: %w", err)
+			}
+			return nil
+		}
+		categories, err := queries.ReadAllCategories(ctx)
+		if err != nil {
+			return fmt.Errorf("error getting categories: %w", err)
+		}
+		categoryMap := make(map[string]int64)
+		var categoryNames []string
+		for _, category := range categories {
+			categoryMap[category.Name] = category.ID
+			categoryNames = append(categoryNames, category.Name)
+		}
+		t := table.NewWriter()
+		t.SetStyle(table.StyleLight)
+		t.AppendHeader(table.Row{"Date", "Account", "Payee", "Amount"})
+		t.AppendRow([]interface{}{transaction.Date, accountKeyToName(transaction.AccountName),
+			payeeName(transaction), fmt.Sprintf("%.2f", transaction.Amount)})
+		label := t.Render()
+
+		// Amounts are compared in cents, so that the splits add up exactly.
+		totalCents := toCents(transaction.Amount)
+		if totalCents == 0 {
+			return errors.New("can't split a transaction with no amount")
+		}
+		var splits []transactionSplit
+		var splitCents int64
+		for splitCents != totalCents {
+			remaining := float64(totalCents-splitCents) / 100
+			category, err := promptCategory(fmt.Sprintf("%s\nSplit %d, %.2f remaining. Category", label, len(splits)+1, remaining),
+				categoryNames, nil)
+			if err != nil {
+				return err
+			}
+			amountPrompt := promptui.Prompt{
+				Label:     "Amount",
+				Default:   strconv.FormatFloat(remaining, 'f', 2, 64),
+				AllowEdit: true,
+				Validate: func(s string) error {
+					amount, err := strconv.ParseFloat(s, 64)
+					if err != nil {
+						return errors.New("amount must be a number")
+					}
+					cents := toCents(amount)
+					if cents == 0 || (cents < 0) != (totalCents < 0) {
+						return errors.New("amount must be non-zero and have the same sign as the transaction")
+					}
+					if math.Abs(float64(cents)) > math.Abs(float64(totalCents-splitCents)) {
+						return fmt.Errorf("amount can't be more than the remaining %.2f", remaining)
+					}
+					return nil
+				},
+			}
+			amountStr, err := amountPrompt.Run()
+			if err != nil {
+				return fmt.Errorf("prompt failed %w", err)
+			}
+			amount, _ := strconv.ParseFloat(amountStr, 64)
+			memoPrompt := promptui.Prompt{Label: "Memo (optional)"}
+			memo, err := memoPrompt.Run()
+			if err != nil {
+				return fmt.Errorf("prompt failed %w", err)
+			}
+			splits = append(splits, transactionSplit{category: category, amount: float64(toCents(amount)) / 100, memo: memo})
+			splitCents += toCents(amount)
+		}
+		renderSplitTable(splits)
+		confirmPrompt := promptui.Prompt{Label: "Save these splits", IsConfirm: true}
+		if _, err := confirmPrompt.Run(); err != nil {
+			if errors.Is(err, promptui.ErrAbort) {
+				return nil
+			}
+			return fmt.Errorf("prompt failed %w", err)
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("error beginning db transaction: %w", err)
+		}
+		defer tx.Rollback()
+		txQueries := models.New(tx)
+		if err := txQueries.DeleteTransactionSplits(ctx, transactionId); err != nil {
+			return fmt.Errorf("error deleting splits: %w", err)
+		}
+		for _, split := range splits {
+			err := txQueries.CreateTransactionSplit(ctx, models.CreateTransactionSplitParams{
+				TransactionID: transactionId,
+				CategoryID:    sql.NullInt64{Valid: true, Int64: categoryMap[split.category]},
+				Amount:        split.amount,
+				Memo:          sql.NullString{Valid: split.memo != "", String: split.memo},
+			})
+			if err != nil {
+				return fmt.Errorf("error creating split: %w", err)
+			}
+		}
+		return tx.Commit()
+	},
+}
+
+func init() {
+	transactionCmd.AddCommand(transactionSplitCmd)
+	transactionSplitCmd.Flags().Bool("clear", false, "Remove the transaction's splits")
+}
+
+func renderSplitTable(splits []transactionSplit) {
+	t := table.NewWriter()
+	t.SetStyle(table.StyleLight)
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Category", "Amount", "Memo"})
+	for _, split := range splits {
+		t.AppendRow([]interface{}{split.category, fmt.Sprintf("%.2f", split.amount), split.memo})
+	}
+	t.Render()
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
@@ -0,0 +1,79 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var transactionTagCmd = &cobra.Command{
+	Use:   "tag",
+	Args:  cobra.MinimumNArgs(1),
+	Short: "Adds or removes tags of transactions. trackit transaction tag <id> [<id>...] --add <tag> --remove <tag>",
+	Long: `Adds tags to, or removes tags from, one or more transactions. Tags are free-form labels, such as
+vacation-2026 or reimbursable, that cut across categories. Tags that don't exist yet are created. E.g.:
+
+$ trackit transaction tag 12 13 14 --add vacation-2026 --add kids
+$ trackit transaction tag 12 --remove kids`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		add, _ := cmd.Flags().GetStringSlice("add")
+		remove, _ := cmd.Flags().GetStringSlice("remove")
+		add, remove = splitTags(strings.Join(add, ",")), splitTags(strings.Join(remove, ","))
+		if len(add) == 0 && len(remove) == 0 {
+			return errors.New("pass tags to --add or --remove")
+		}
+		var ids []int64
+		for _, arg := range args {
+			id, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return fmt.Errorf("error parsing transaction id %s: %w", arg, err)
+			}
+			ids = append(ids, id)
+		}
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		ctx := context.Background()
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("error beginning db transaction: %w", err)
+		}
+		defer tx.Rollback()
+		queries := models.New(tx)
+		for _, id := range ids {
+			if _, err := queries.ReadTransactionById(ctx, id); err != nil {
+				return fmt.Errorf("error getting transaction %d: %w", id, err)
+			}
+			if err := addTransactionTags(ctx, queries, id, add); err != nil {
+				return err
+			}
+			for _, tag := range remove {
+				err := queries.DeleteTransactionTag(ctx, models.DeleteTransactionTagParams{TransactionID: id, Name: tag})
+				if err != nil {
+					return fmt.Errorf("error removing tag %s from transaction %d: %w", tag, id, err)
+				}
+			}
+		}
+		return tx.Commit()
+	},
+}
+
+func init() {
+	transactionCmd.AddCommand(transactionTagCmd)
+	transactionTagCmd.Flags().StringSlice("add", nil, "Tag to add. Pass multiple --add flags, or separate tags with commas, for multiple tags")
+	transactionTagCmd.Flags().StringSlice("remove", nil, "Tag to remove. Pass multiple --remove flags, or separate tags with commas, for multiple tags")
+}
@@ -0,0 +1,177 @@
+/*
+Copyright © 2025 Aaron Cohen <aaroncohendev@gmail.com>
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/kahunacohen/trackit/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var transactionUpdateCmd = &cobra.Command{
+	Use:     "update",
+	Aliases: []string{"edit"},
+	Args:    cobra.ExactArgs(1),
+	Short:   "Updates a transaction. trackit transaction update <id> [--date] [--amount] [--payee] [--description] [--account] [--category]",
+	Long: `Updates the fields of a transaction that are passed, leaving the others as they are. As with
+trackit transaction create, the amount is in the base currency, and negative for money going out. A
+category, by name or ID, is kept when rules are applied again, and passing an empty one un-categorizes
+the transaction. E.g.:
+
+$ trackit transaction update 42 --amount -54.20 --category Groceries
+$ trackit transaction update 42 --payee "WHOLE FOODS" --description ""
+
+Changing the amount of a split transaction requires --clear-splits, as its splits would no longer add
+up. The changes are recorded in the transaction's history, shown by trackit transaction show.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		if !flags.Changed("date") && !flags.Changed("amount") && !flags.Changed("payee") && !flags.Changed("description") &&
+			!flags.Changed("account") && !flags.Changed("category") {
+			return errors.New("pass the fields to update, e.g. --amount or --category")
+		}
+		clearSplits, _ := flags.GetBool("clear-splits")
+		transactionId, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("error parsing transaction id: %w", err)
+		}
+		_, _, dbPath, err := getDataPaths()
+		if err != nil {
+			return err
+		}
+		db, err := getDB(dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		ctx := context.Background()
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("error beginning db transaction: %w", err)
+		}
+		defer tx.Rollback()
+		queries := models.New(tx)
+		if _, err := readTransaction(ctx, queries, transactionId); err != nil {
+			return err
+		}
+		transaction, err := queries.ReadRawTransactionById(ctx, transactionId)
+		if err != nil {
+			return fmt.Errorf("error getting transaction %d: %w", transactionId, err)
+		}
+		params := models.UpdateTransactionParams{
+			ID:                  transactionId,
+			AccountID:           transaction.AccountID,
+			Date:                transaction.Date,
+			Amount:              transaction.Amount,
+			CounterParty:        transaction.CounterParty,
+			PayeeID:             transaction.PayeeID,
+			Description:         transaction.Description,
+			CategoryID:          transaction.CategoryID,
+			CategorizedManually: transaction.CategorizedManually,
+		}
+		if flags.Changed("date") {
+			params.Date, _ = flags.GetString("date")
+			if !validateDateWithDayFormat(params.Date) {
+				return fmt.Errorf("date '%s' is invalid. Must be in form: YYYY-MM-DD", params.Date)
+			}
+		}
+		if flags.Changed("amount") {
+			params.Amount, _ = flags.GetFloat64("amount")
+			if toCents(params.Amount) == 0 {
+				return errors.New("amount can't be 0")
+			}
+			if toCents(params.Amount) != toCents(transaction.Amount) {
+				if err := clearChangedSplits(ctx, queries, transactionId, params.Amount, clearSplits); err != nil {
+					return err
+				}
+			}
+		}
+		if flags.Changed("payee") {
+			params.CounterParty, _ = flags.GetString("payee")
+			if params.CounterParty == "" {
+				return errors.New("payee can't be empty")
+			}
+			payees, err := newPayeeResolver(ctx, queries)
+			if err != nil {
+				return err
+			}
+			params.PayeeID = payees.resolve(params.CounterParty)
+		}
+		if flags.Changed("description") {
+			description, _ := flags.GetString("description")
+			params.Description = sql.NullString{Valid: description != "", String: description}
+		}
+		if flags.Changed("account") {
+			account, _ := flags.GetString("account")
+			accountId, err := queries.ReadAccountIdByName(ctx, account)
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("account %s doesn't exist. Must be an account key in trackit.yaml", account)
+			} else if err != nil {
+				return fmt.Errorf("error getting account ID: %w", err)
+			}
+			params.AccountID = sql.NullInt64{Valid: true, Int64: accountId}
+		}
+		if flags.Changed("category") {
+			category, _ := flags.GetString("category")
+			params.CategoryID, params.CategorizedManually = sql.NullInt64{}, 0
+			if category != "" {
+				tree, err := readCategoryTree(ctx, queries)
+				if err != nil {
+					return err
+				}
+				categoryId, err := tree.categoryId(category)
+				if err != nil {
+					return err
+				}
+				params.CategoryID, params.CategorizedManually = sql.NullInt64{Valid: true, Int64: categoryId}, 1
+			}
+		}
+		if err := queries.UpdateTransaction(ctx, params); err != nil {
+			return fmt.Errorf("error updating transaction: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("error committing transaction update: %w", err)
+		}
+		updated, err := readTransaction(ctx, models.New(db), transactionId)
+		if err != nil {
+			return err
+		}
+		return renderTransactionTable([]models.TransactionsView{updated})
+	},
+}
+
+func init() {
+	transactionCmd.AddCommand(transactionUpdateCmd)
+	transactionUpdateCmd.Flags().StringP("date", "e", "", "Date in YYYY-MM-DD format")
+	transactionUpdateCmd.Flags().Float64P("amount", "m", 0, "Amount of the transaction")
+	transactionUpdateCmd.Flags().StringP("payee", "p", "", "Counter party of the transaction, as in bank statements. Its payee is resolved from payee aliases")
+	transactionUpdateCmd.Flags().StringP("description", "d", "", "Description of the transaction. Pass an empty one to remove it")
+	transactionUpdateCmd.Flags().StringP("account", "a", "", "Account key")
+	transactionUpdateCmd.Flags().StringP("category", "t", "", "Category name or ID. Pass an empty one to un-categorize the transaction")
+	transactionUpdateCmd.Flags().Bool("clear-splits", false, "Remove the transaction's splits when changing its amount")
+}
+
+// clearChangedSplits removes the splits of a transaction whose amount is changed, which
+// must be allowed with --clear-splits, as they would no longer add up to it.
+func clearChangedSplits(ctx context.Context, queries *models.Queries, transactionId int64, amount float64, clearSplits bool) error {
+	splits, err := queries.ReadTransactionSplits(ctx, transactionId)
+	if err != nil {
+		return fmt.Errorf("error getting splits: %w", err)
+	}
+	if len(splits) == 0 {
+		return nil
+	}
+	if !clearSplits {
+		return fmt.Errorf("transaction %d is split, and its splits wouldn't add up to %.2f. Pass --clear-splits to remove them, then split it again with trackit transaction split",
+			transactionId, amount)
+	}
+	if err := queries.DeleteTransactionSplits(ctx, transactionId); err != nil {
+		return fmt.Errorf("error deleting splits: %w", err)
+	}
+	return nil
+}
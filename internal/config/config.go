@@ -1,23 +1,129 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+type Header struct {
+	Name  string `yaml:"name"`
+	Table string `yaml:"table"`
+	// Aliases are other names the bank has used for this column, e.g. "Posting Date"
+	// for "Posted Date".
+	Aliases []string `yaml:"aliases"`
+	// Index is the zero-based position of the column. It's only needed for files without
+	// a header row, and defaults to the position of the header in the headers list.
+	Index *int `yaml:"index"`
+}
+
+// Matches reports whether a column name from a CSV file's header row is this header or one
+// of its aliases. Case, surrounding whitespace and a byte order mark are ignored.
+func (h Header) Matches(column string) bool {
+	column = normalizeColumnName(column)
+	if column == normalizeColumnName(h.Name) {
+		return true
+	}
+	for _, alias := range h.Aliases {
+		if column == normalizeColumnName(alias) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeColumnName(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+}
+
 type Account struct {
-	Currency           string              `yaml:"currency"`
-	DateLayout         string              `yaml:"date_layout"`
-	DebitAsPositive    bool                `yaml:"debit_as_positive"`
-	Headers            []map[string]string `yaml:"headers"`
-	ThousandsSeparator string              `yaml:"thousands_separator"`
+	// CounterPartyPatterns are regular expressions that extract a clean counter party from
+	// noisy bank text, e.g. "POS 1234 SUPERMARKET TLV 05/03". The first pattern that matches
+	// wins, and its "payee" named group, or else its first group, is the counter party.
+	CounterPartyPatterns []string `yaml:"counter_party_patterns"`
+	// CounterPartyTemplate builds the counter party from several columns, referenced by
+	// header name in braces, e.g. "{Payee} {Details}".
+	CounterPartyTemplate string   `yaml:"counter_party_template"`
+	Currency             string   `yaml:"currency"`
+	DateLayout           string   `yaml:"date_layout"`
+	DebitAsPositive      bool     `yaml:"debit_as_positive"`
+	DecimalSeparator     string   `yaml:"decimal_separator"`
+	HasHeader            *bool    `yaml:"has_header"`
+	Headers              []Header `yaml:"headers"`
+	Preset               string   `yaml:"preset"`
+	ThousandsSeparator   string   `yaml:"thousands_separator"`
 }
+
+// HeaderRow reports whether the account's CSV files start with a header row, which is
+// the default.
+func (a Account) HeaderRow() bool {
+	return a.HasHeader == nil || *a.HasHeader
+}
+
+// ColumnIndex returns the position of the i-th configured header in a data row of a
+// file without a header row.
+func (a Account) ColumnIndex(i int) int {
+	if a.Headers[i].Index != nil {
+		return *a.Headers[i].Index
+	}
+	return i
+}
+
+// HeaderIndex returns the position of the column called name, or -1 if there is none.
+// The name may be a configured header, one of its aliases, or, for files with a header
+// row, any other column in headerRow.
+func (a Account) HeaderIndex(headerRow []string, name string) int {
+	for i, header := range a.Headers {
+		if !header.Matches(name) {
+			continue
+		}
+		if !a.HeaderRow() {
+			return a.ColumnIndex(i)
+		}
+		return slices.IndexFunc(headerRow, header.Matches)
+	}
+	if !a.HeaderRow() {
+		return -1
+	}
+	return slices.IndexFunc(headerRow, Header{Name: name}.Matches)
+}
+
+// ColumnIndices maps each table to the position of its column in the account's CSV files,
+// e.g. {"transaction_date": 0, "counter_party": 3, "amount": 4}. For files with a header
+// row, columns are looked up by name in headerRow, so they may be in any order. Headers
+// that don't map to a table may be missing from the file.
+func (a Account) ColumnIndices(headerRow []string) (map[string]int, error) {
+	colIndexMap := make(map[string]int)
+	for i, header := range a.Headers {
+		tableName := header.Table
+		if tableName != "transaction_date" && tableName != "counter_party" && tableName != "amount" && tableName != "deposit" && tableName != "withdrawl" {
+			continue
+		}
+		if !a.HeaderRow() {
+			colIndexMap[tableName] = a.ColumnIndex(i)
+			continue
+		}
+		index := slices.IndexFunc(headerRow, header.Matches)
+		if index == -1 {
+			names := append([]string{header.Name}, header.Aliases...)
+			return nil, fmt.Errorf("no column named '%s' for %s", strings.Join(names, "' or '"), tableName)
+		}
+		colIndexMap[tableName] = index
+	}
+	return colIndexMap, nil
+}
+
 type Config struct {
 	Accounts     map[string]Account  `yaml:"accounts"`
 	BaseCurrency string              `yaml:"base_currency"`
 	Categories   map[string][]string `yaml:"categories"`
+	Tags         map[string][]string `yaml:"tags"`
 }
 
 func ParseConfig(path string) (*Config, error) {
@@ -26,36 +132,178 @@ func ParseConfig(path string) (*Config, error) {
 		return nil, fmt.Errorf("could not read config file at %s: %w", path, err)
 	}
 
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("error parsing config: %w", err)
+	}
 	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	if len(doc.Content) == 0 {
+		return &config, nil
+	}
+	if err := applyPresets(doc.Content[0]); err != nil {
+		return nil, fmt.Errorf("error applying presets: %w", err)
+	}
+	if err := doc.Decode(&config); err != nil {
 		return nil, fmt.Errorf("error parsing config: %w", err)
 	}
 	return &config, nil
 }
 
-// Returns a map keyed by account name whose value is another map, mapping table
-// name to the position in the slice of headers. E.g.:
-// {accountName: {"transaction_date": 0, "counter_party": 3, "amount": 4}}
-func (c *Config) AccountColumnIndices() map[string]map[string]int {
-	accountToColIndices := make(map[string]map[string]int)
-	for accountName, account := range c.Accounts {
-		colIndexMap := make(map[string]int)
-		for i, headerMap := range account.Headers {
-			tableName := headerMap["table"]
-			if tableName == "transaction_date" || tableName == "counter_party" || tableName == "amount" || tableName == "deposit" || tableName == "withdrawl" {
-				colIndexMap[tableName] = i
+// AddAccount appends a new account block to the accounts mapping of the config file
+// at path, creating the file if it doesn't exist. It edits the parsed YAML node tree
+// rather than re-marshalling the Config struct so existing comments and key order are
+// kept intact.
+func AddAccount(path string, name string, account Account) error {
+	doc, err := readConfigNode(path)
+	if err != nil {
+		return err
+	}
+	root := doc.Content[0]
+	accounts := mappingValue(root, "accounts")
+	if accounts == nil || accounts.Kind != yaml.MappingNode {
+		accounts = &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(root, "accounts", accounts)
+	}
+	if mappingValue(accounts, name) != nil {
+		return fmt.Errorf("account '%s' already exists in %s", name, path)
+	}
+	accounts.Content = append(accounts.Content, scalarNode(name), accountNode(account))
+	return writeConfigNode(path, doc)
+}
+
+// AddCategories adds categories, and regular expressions of categories, to the categories
+// mapping of the config file at path. Categories that are already in it keep their
+// regular expressions, and get the new ones appended. Like AddAccount, it keeps existing
+// comments and key order intact.
+func AddCategories(path string, categories map[string][]string) error {
+	doc, err := readConfigNode(path)
+	if err != nil {
+		return err
+	}
+	root := doc.Content[0]
+	mapping := mappingValue(root, "categories")
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		mapping = &yaml.Node{Kind: yaml.MappingNode}
+		setMappingValue(root, "categories", mapping)
+	}
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		patterns := mappingValue(mapping, name)
+		if patterns == nil || patterns.Kind != yaml.SequenceNode {
+			patterns = &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+			setMappingValue(mapping, name, patterns)
+		}
+		for _, pattern := range categories[name] {
+			if slices.ContainsFunc(patterns.Content, func(node *yaml.Node) bool { return node.Value == pattern }) {
+				continue
 			}
+			patterns.Style = 0
+			patterns.Content = append(patterns.Content, scalarNode(pattern))
+		}
+	}
+	return writeConfigNode(path, doc)
+}
+
+// readConfigNode parses the config file at path into a YAML node tree, whose root is a
+// mapping. A missing or empty file is an empty mapping.
+func readConfigNode(path string) (*yaml.Node, error) {
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("could not read config file at %s: %w", path, err)
+	}
+	if len(data) > 0 {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("error parsing config: %w", err)
+		}
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("config file at %s is not a YAML mapping", path)
+	}
+	return &doc, nil
+}
+
+func writeConfigNode(path string, doc *yaml.Node) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("could not open config file at %s for writing: %w", path, err)
+	}
+	defer out.Close()
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("error writing config: %w", err)
+	}
+	return enc.Close()
+}
+
+func accountNode(account Account) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	if account.Currency != "" {
+		node.Content = append(node.Content, scalarNode("currency"), scalarNode(account.Currency))
+	}
+	node.Content = append(node.Content, scalarNode("date_layout"), scalarNode(account.DateLayout))
+	if account.DebitAsPositive {
+		node.Content = append(node.Content, scalarNode("debit_as_positive"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+	}
+	if !account.HeaderRow() {
+		node.Content = append(node.Content, scalarNode("has_header"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "false"})
+	}
+	if account.ThousandsSeparator != "" {
+		node.Content = append(node.Content, scalarNode("thousands_separator"),
+			&yaml.Node{Kind: yaml.ScalarNode, Style: yaml.DoubleQuotedStyle, Value: account.ThousandsSeparator})
+	}
+	if account.DecimalSeparator != "" && account.DecimalSeparator != "." {
+		node.Content = append(node.Content, scalarNode("decimal_separator"),
+			&yaml.Node{Kind: yaml.ScalarNode, Style: yaml.DoubleQuotedStyle, Value: account.DecimalSeparator})
+	}
+	headers := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, header := range account.Headers {
+		table := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "~"}
+		if header.Table != "" {
+			table = scalarNode(header.Table)
+		}
+		headerNode := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+			scalarNode("name"), scalarNode(header.Name),
+			scalarNode("table"), table,
+		}}
+		if header.Index != nil {
+			headerNode.Content = append(headerNode.Content, scalarNode("index"),
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(*header.Index)})
 		}
-		accountToColIndices[accountName] = colIndexMap
+		headers.Content = append(headers.Content, headerNode)
 	}
-	return accountToColIndices
+	node.Content = append(node.Content, scalarNode("headers"), headers)
+	return node
 }
 
-func (c *Config) Headers(accountName string) []string {
-	headers := c.Accounts[accountName].Headers
-	ret := make([]string, len(headers))
-	for i, h := range headers {
-		ret[i] = h["name"]
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// mappingValue returns the value node for key in a YAML mapping node, or nil.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
 	}
-	return ret
+	mapping.Content = append(mapping.Content, scalarNode(key), value)
 }
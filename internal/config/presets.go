@@ -0,0 +1,100 @@
+package config
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Presets are account blocks for common bank CSV formats, shipped in the binary. An
+// account in trackit.yaml can reference one with `preset: <name>` and override any of
+// its fields. To contribute a preset, add a YAML file to the presets directory.
+//
+//go:embed presets/*.yaml
+var presetFS embed.FS
+
+type Preset struct {
+	Name        string
+	Description string
+	Account     Account
+	// Source is the preset's YAML as shipped.
+	Source string
+}
+
+type presetFile struct {
+	Account     `yaml:",inline"`
+	Description string `yaml:"description"`
+}
+
+// Presets returns all built-in presets sorted by name.
+func Presets() ([]Preset, error) {
+	entries, err := fs.ReadDir(presetFS, "presets")
+	if err != nil {
+		return nil, fmt.Errorf("error reading presets: %w", err)
+	}
+	var presets []Preset
+	for _, entry := range entries {
+		preset, err := ReadPreset(strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())))
+		if err != nil {
+			return nil, err
+		}
+		presets = append(presets, *preset)
+	}
+	sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+	return presets, nil
+}
+
+// ReadPreset reads a built-in preset by name.
+func ReadPreset(name string) (*Preset, error) {
+	data, err := presetFS.ReadFile(path.Join("presets", name+".yaml"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("no preset named '%s'. See trackit preset list", name)
+		}
+		return nil, fmt.Errorf("error reading preset %s: %w", name, err)
+	}
+	var file presetFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error parsing preset %s: %w", name, err)
+	}
+	return &Preset{Name: name, Description: file.Description, Account: file.Account, Source: string(data)}, nil
+}
+
+// applyPresets replaces every account mapping that has a preset key with the preset's
+// mapping, overlaid with the keys set on the account itself.
+func applyPresets(root *yaml.Node) error {
+	accounts := mappingValue(root, "accounts")
+	if accounts == nil || accounts.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 1; i < len(accounts.Content); i += 2 {
+		account := accounts.Content[i]
+		if account.Kind != yaml.MappingNode {
+			continue
+		}
+		presetName := mappingValue(account, "preset")
+		if presetName == nil || presetName.Value == "" {
+			continue
+		}
+		preset, err := ReadPreset(presetName.Value)
+		if err != nil {
+			return fmt.Errorf("account %s: %w", accounts.Content[i-1].Value, err)
+		}
+		var merged yaml.Node
+		if err := yaml.Unmarshal([]byte(preset.Source), &merged); err != nil {
+			return fmt.Errorf("error parsing preset %s: %w", preset.Name, err)
+		}
+		mapping := merged.Content[0]
+		for j := 0; j+1 < len(account.Content); j += 2 {
+			setMappingValue(mapping, account.Content[j].Value, account.Content[j+1])
+		}
+		accounts.Content[i] = mapping
+	}
+	return nil
+}
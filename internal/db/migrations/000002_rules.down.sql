@@ -0,0 +1,3 @@
+drop table if exists rules;
+drop table if exists transaction_tags;
+drop table if exists tags;
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name" TEXT UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS transaction_tags (
+    transaction_id INTEGER NOT NULL,
+    tag_id INTEGER NOT NULL,
+    PRIMARY KEY (transaction_id, tag_id),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+-- Categorization rules, applied in ascending priority when transactions are imported
+-- or created. Every condition that is set must match. The first matching rule with a
+-- category (or ignore flag) sets it, and tags are added from all matching rules.
+CREATE TABLE IF NOT EXISTS rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    priority INTEGER NOT NULL,
+    -- conditions
+    counter_party TEXT,
+    "description" TEXT,
+    account_id INTEGER,
+    min_amount REAL,
+    max_amount REAL,
+    sign TEXT CHECK (sign IN ('positive', 'negative')),
+    from_date TEXT,
+    to_date TEXT,
+    -- actions
+    category_id INTEGER,
+    ignore_when_summing INTEGER CHECK (ignore_when_summing IN (0, 1)),
+    tags TEXT,
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
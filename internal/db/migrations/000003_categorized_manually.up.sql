@@ -0,0 +1,24 @@
+-- Whether the category was chosen by hand, in which case re-applying the categorization
+-- rules leaves it alone.
+ALTER TABLE transactions ADD COLUMN categorized_manually INTEGER NOT NULL DEFAULT 0 CHECK (categorized_manually IN (0, 1));
+
+DROP VIEW IF EXISTS transactions_view;
+
+CREATE VIEW transactions_view AS
+SELECT 
+    accounts.id AS account_id,
+    accounts.name AS account_name, 
+    transactions.id AS transaction_id, 
+	transactions.date AS date, 
+    transactions.counter_party AS counter_party, 
+    transactions.amount AS amount,
+    transactions.ignore_when_summing as ignore_when_summing,
+    transactions.description AS "description",
+    categories.name AS category_name,
+    transactions.categorized_manually AS categorized_manually
+FROM 
+    transactions
+LEFT JOIN 
+    accounts ON transactions.account_id = accounts.id
+LEFT JOIN 
+    categories ON transactions.category_id = categories.id;
@@ -0,0 +1,25 @@
+DROP VIEW IF EXISTS transactions_view;
+
+ALTER TABLE transactions DROP COLUMN payee_id;
+
+DROP TABLE IF EXISTS payee_aliases;
+DROP TABLE IF EXISTS payees;
+
+CREATE VIEW transactions_view AS
+SELECT 
+    accounts.id AS account_id,
+    accounts.name AS account_name, 
+    transactions.id AS transaction_id, 
+	transactions.date AS date, 
+    transactions.counter_party AS counter_party, 
+    transactions.amount AS amount,
+    transactions.ignore_when_summing as ignore_when_summing,
+    transactions.description AS "description",
+    categories.name AS category_name,
+    transactions.categorized_manually AS categorized_manually
+FROM 
+    transactions
+LEFT JOIN 
+    accounts ON transactions.account_id = accounts.id
+LEFT JOIN 
+    categories ON transactions.category_id = categories.id;
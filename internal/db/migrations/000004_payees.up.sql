@@ -0,0 +1,40 @@
+-- Payees are the canonical names of counter parties. A transaction's raw counter party
+-- is kept as is, and linked to the payee whose first alias regular expression matches it.
+CREATE TABLE IF NOT EXISTS payees (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name" TEXT UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS payee_aliases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    payee_id INTEGER NOT NULL,
+    pattern TEXT UNIQUE NOT NULL,
+    FOREIGN KEY (payee_id) REFERENCES payees(id) ON DELETE CASCADE
+);
+
+ALTER TABLE transactions ADD COLUMN payee_id INTEGER REFERENCES payees(id) ON DELETE SET NULL;
+
+DROP VIEW IF EXISTS transactions_view;
+
+CREATE VIEW transactions_view AS
+SELECT 
+    accounts.id AS account_id,
+    accounts.name AS account_name, 
+    transactions.id AS transaction_id, 
+	transactions.date AS date, 
+    transactions.counter_party AS counter_party, 
+    transactions.amount AS amount,
+    transactions.ignore_when_summing as ignore_when_summing,
+    transactions.description AS "description",
+    categories.name AS category_name,
+    transactions.categorized_manually AS categorized_manually,
+    payees.id AS payee_id,
+    payees.name AS payee_name
+FROM 
+    transactions
+LEFT JOIN 
+    accounts ON transactions.account_id = accounts.id
+LEFT JOIN 
+    categories ON transactions.category_id = categories.id
+LEFT JOIN 
+    payees ON transactions.payee_id = payees.id;
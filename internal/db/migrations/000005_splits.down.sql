@@ -0,0 +1,2 @@
+DROP VIEW IF EXISTS transaction_categories_view;
+DROP TABLE IF EXISTS transaction_splits;
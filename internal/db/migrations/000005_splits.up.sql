@@ -0,0 +1,40 @@
+-- Splits divide a transaction's amount across categories. The split amounts of a
+-- transaction add up to its amount.
+CREATE TABLE IF NOT EXISTS transaction_splits (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_id INTEGER NOT NULL,
+    category_id INTEGER,
+    amount REAL NOT NULL,
+    memo TEXT,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE SET NULL
+);
+
+-- One row per category a transaction's amount is counted in: the transaction itself when
+-- it isn't split, otherwise one row per split. Aggregations by category sum this view.
+CREATE VIEW transaction_categories_view AS
+SELECT
+    account_name,
+    transaction_id,
+    date,
+    amount,
+    ignore_when_summing,
+    category_name
+FROM
+    transactions_view
+WHERE
+    transaction_id NOT IN (SELECT transaction_id FROM transaction_splits)
+UNION ALL
+SELECT
+    transactions_view.account_name AS account_name,
+    transactions_view.transaction_id AS transaction_id,
+    transactions_view.date AS date,
+    transaction_splits.amount AS amount,
+    transactions_view.ignore_when_summing AS ignore_when_summing,
+    categories.name AS category_name
+FROM
+    transaction_splits
+JOIN
+    transactions_view ON transactions_view.transaction_id = transaction_splits.transaction_id
+LEFT JOIN
+    categories ON transaction_splits.category_id = categories.id;
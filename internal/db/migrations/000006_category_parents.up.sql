@@ -0,0 +1,2 @@
+-- Categories form a tree. Top level categories have no parent.
+ALTER TABLE categories ADD COLUMN parent_id INTEGER REFERENCES categories(id) ON DELETE SET NULL;
@@ -0,0 +1,35 @@
+-- The kind of a category decides which total its amounts count towards. Transfers, e.g.
+-- between your own accounts, aren't counted as income or spending.
+ALTER TABLE categories ADD COLUMN kind TEXT NOT NULL DEFAULT 'expense' CHECK (kind IN ('income', 'expense', 'transfer', 'savings'));
+
+UPDATE categories SET kind='savings' WHERE name IN ('Investments', 'Savings');
+
+INSERT OR IGNORE INTO categories (name, kind) VALUES
+('Income', 'income'),
+('Transfers', 'transfer');
+
+DROP VIEW IF EXISTS transactions_view;
+
+CREATE VIEW transactions_view AS
+SELECT 
+    accounts.id AS account_id,
+    accounts.name AS account_name, 
+    transactions.id AS transaction_id, 
+	transactions.date AS date, 
+    transactions.counter_party AS counter_party, 
+    transactions.amount AS amount,
+    transactions.ignore_when_summing as ignore_when_summing,
+    transactions.description AS "description",
+    categories.name AS category_name,
+    transactions.categorized_manually AS categorized_manually,
+    payees.id AS payee_id,
+    payees.name AS payee_name,
+    categories.kind AS category_kind
+FROM 
+    transactions
+LEFT JOIN 
+    accounts ON transactions.account_id = accounts.id
+LEFT JOIN 
+    categories ON transactions.category_id = categories.id
+LEFT JOIN 
+    payees ON transactions.payee_id = payees.id;
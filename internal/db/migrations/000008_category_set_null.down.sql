@@ -0,0 +1,79 @@
+DROP VIEW IF EXISTS transaction_categories_view;
+DROP VIEW IF EXISTS transactions_view;
+
+CREATE TABLE transactions_old (
+    id INTEGER PRIMARY KEY,
+    account_id INTEGER,
+    category_id INTEGER,
+    counter_party TEXT NOT NULL,
+    "description" TEXT,
+    amount REAL NOT NULL,
+    deposit REAL,
+    withdrawl REAL,
+    ignore_when_summing INTEGER NOT NULL DEFAULT 0 CHECK (ignore_when_summing IN (0, 1)),
+    "date" TEXT NOT NULL,
+    categorized_manually INTEGER NOT NULL DEFAULT 0 CHECK (categorized_manually IN (0, 1)),
+    payee_id INTEGER REFERENCES payees(id) ON DELETE SET NULL,
+    FOREIGN KEY (account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+);
+
+INSERT INTO transactions_old (id, account_id, category_id, counter_party, "description", amount, deposit, withdrawl, ignore_when_summing, "date", categorized_manually, payee_id)
+SELECT id, account_id, category_id, counter_party, "description", amount, deposit, withdrawl, ignore_when_summing, "date", categorized_manually, payee_id FROM transactions;
+
+DROP TABLE transactions;
+
+ALTER TABLE transactions_old RENAME TO transactions;
+
+CREATE VIEW transactions_view AS
+SELECT 
+    accounts.id AS account_id,
+    accounts.name AS account_name, 
+    transactions.id AS transaction_id, 
+	transactions.date AS date, 
+    transactions.counter_party AS counter_party, 
+    transactions.amount AS amount,
+    transactions.ignore_when_summing as ignore_when_summing,
+    transactions.description AS "description",
+    categories.name AS category_name,
+    transactions.categorized_manually AS categorized_manually,
+    payees.id AS payee_id,
+    payees.name AS payee_name,
+    categories.kind AS category_kind
+FROM 
+    transactions
+LEFT JOIN 
+    accounts ON transactions.account_id = accounts.id
+LEFT JOIN 
+    categories ON transactions.category_id = categories.id
+LEFT JOIN 
+    payees ON transactions.payee_id = payees.id;
+
+-- One row per category a transaction's amount is counted in: the transaction itself when
+-- it isn't split, otherwise one row per split. Aggregations by category sum this view.
+CREATE VIEW transaction_categories_view AS
+SELECT
+    account_name,
+    transaction_id,
+    date,
+    amount,
+    ignore_when_summing,
+    category_name
+FROM
+    transactions_view
+WHERE
+    transaction_id NOT IN (SELECT transaction_id FROM transaction_splits)
+UNION ALL
+SELECT
+    transactions_view.account_name AS account_name,
+    transactions_view.transaction_id AS transaction_id,
+    transactions_view.date AS date,
+    transaction_splits.amount AS amount,
+    transactions_view.ignore_when_summing AS ignore_when_summing,
+    categories.name AS category_name
+FROM
+    transaction_splits
+JOIN
+    transactions_view ON transactions_view.transaction_id = transaction_splits.transaction_id
+LEFT JOIN
+    categories ON transaction_splits.category_id = categories.id;
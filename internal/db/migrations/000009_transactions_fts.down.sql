@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS payees_fts_update;
+DROP TRIGGER IF EXISTS categories_fts_update;
+DROP TRIGGER IF EXISTS transaction_splits_fts_delete;
+DROP TRIGGER IF EXISTS transaction_splits_fts_update;
+DROP TRIGGER IF EXISTS transaction_splits_fts_insert;
+DROP TRIGGER IF EXISTS transactions_fts_delete;
+DROP TRIGGER IF EXISTS transactions_fts_update;
+DROP TRIGGER IF EXISTS transactions_fts_insert;
+DROP VIEW IF EXISTS transactions_fts_view;
+DROP TABLE IF EXISTS transactions_fts;
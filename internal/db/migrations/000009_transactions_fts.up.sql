@@ -0,0 +1,91 @@
+-- Full-text index of transactions, searched by trackit transaction search. Its category
+-- column has the names of the transaction's category and those of its splits, and notes
+-- the memos of its splits. FTS5 must be compiled in, with the sqlite_fts5 build tag.
+CREATE VIRTUAL TABLE transactions_fts USING fts5(
+    counter_party,
+    payee,
+    "description",
+    category,
+    notes,
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+-- The row of each transaction in transactions_fts.
+CREATE VIEW transactions_fts_view AS
+SELECT
+    transactions.id AS transaction_id,
+    transactions.counter_party AS counter_party,
+    payees.name AS payee,
+    transactions."description" AS "description",
+    (SELECT group_concat(categories.name, ' ') FROM categories
+        WHERE categories.id = transactions.category_id
+        OR categories.id IN (SELECT category_id FROM transaction_splits WHERE transaction_id = transactions.id)) AS category,
+    (SELECT group_concat(memo, ' ') FROM transaction_splits WHERE transaction_id = transactions.id) AS notes
+FROM
+    transactions
+LEFT JOIN
+    payees ON transactions.payee_id = payees.id;
+
+INSERT INTO transactions_fts (rowid, counter_party, payee, "description", category, notes)
+SELECT transaction_id, counter_party, payee, "description", category, notes FROM transactions_fts_view;
+
+-- Triggers re-index a transaction when it, its splits, or the names of its category or
+-- payee change. Deleting a category or payee sets the transactions' IDs to NULL, which
+-- re-indexes them too.
+CREATE TRIGGER transactions_fts_insert AFTER INSERT ON transactions BEGIN
+    INSERT INTO transactions_fts (rowid, counter_party, payee, "description", category, notes)
+    SELECT transaction_id, counter_party, payee, "description", category, notes FROM transactions_fts_view
+    WHERE transaction_id = NEW.id;
+END;
+
+CREATE TRIGGER transactions_fts_update AFTER UPDATE ON transactions BEGIN
+    DELETE FROM transactions_fts WHERE rowid = OLD.id;
+    INSERT INTO transactions_fts (rowid, counter_party, payee, "description", category, notes)
+    SELECT transaction_id, counter_party, payee, "description", category, notes FROM transactions_fts_view
+    WHERE transaction_id = NEW.id;
+END;
+
+CREATE TRIGGER transactions_fts_delete AFTER DELETE ON transactions BEGIN
+    DELETE FROM transactions_fts WHERE rowid = OLD.id;
+END;
+
+CREATE TRIGGER transaction_splits_fts_insert AFTER INSERT ON transaction_splits BEGIN
+    DELETE FROM transactions_fts WHERE rowid = NEW.transaction_id;
+    INSERT INTO transactions_fts (rowid, counter_party, payee, "description", category, notes)
+    SELECT transaction_id, counter_party, payee, "description", category, notes FROM transactions_fts_view
+    WHERE transaction_id = NEW.transaction_id;
+END;
+
+CREATE TRIGGER transaction_splits_fts_update AFTER UPDATE ON transaction_splits BEGIN
+    DELETE FROM transactions_fts WHERE rowid IN (OLD.transaction_id, NEW.transaction_id);
+    INSERT INTO transactions_fts (rowid, counter_party, payee, "description", category, notes)
+    SELECT transaction_id, counter_party, payee, "description", category, notes FROM transactions_fts_view
+    WHERE transaction_id IN (OLD.transaction_id, NEW.transaction_id);
+END;
+
+CREATE TRIGGER transaction_splits_fts_delete AFTER DELETE ON transaction_splits BEGIN
+    DELETE FROM transactions_fts WHERE rowid = OLD.transaction_id;
+    INSERT INTO transactions_fts (rowid, counter_party, payee, "description", category, notes)
+    SELECT transaction_id, counter_party, payee, "description", category, notes FROM transactions_fts_view
+    WHERE transaction_id = OLD.transaction_id;
+END;
+
+CREATE TRIGGER categories_fts_update AFTER UPDATE OF name ON categories BEGIN
+    DELETE FROM transactions_fts WHERE rowid IN (
+        SELECT id FROM transactions WHERE category_id = NEW.id
+        UNION SELECT transaction_id FROM transaction_splits WHERE category_id = NEW.id
+    );
+    INSERT INTO transactions_fts (rowid, counter_party, payee, "description", category, notes)
+    SELECT transaction_id, counter_party, payee, "description", category, notes FROM transactions_fts_view
+    WHERE transaction_id IN (
+        SELECT id FROM transactions WHERE category_id = NEW.id
+        UNION SELECT transaction_id FROM transaction_splits WHERE category_id = NEW.id
+    );
+END;
+
+CREATE TRIGGER payees_fts_update AFTER UPDATE OF name ON payees BEGIN
+    DELETE FROM transactions_fts WHERE rowid IN (SELECT id FROM transactions WHERE payee_id = NEW.id);
+    INSERT INTO transactions_fts (rowid, counter_party, payee, "description", category, notes)
+    SELECT transaction_id, counter_party, payee, "description", category, notes FROM transactions_fts_view
+    WHERE transaction_id IN (SELECT id FROM transactions WHERE payee_id = NEW.id);
+END;
@@ -0,0 +1,8 @@
+-- Saved queries are trackit transaction list, search or aggregate commands saved by name.
+-- args is a JSON array of the command's arguments, which may have {{parameters}}.
+CREATE TABLE IF NOT EXISTS saved_queries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    "name" TEXT UNIQUE NOT NULL,
+    command TEXT NOT NULL CHECK (command IN ('list', 'search', 'aggregate')),
+    args TEXT NOT NULL
+);
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS transactions_history_update;
+DROP TABLE IF EXISTS transaction_history;
+
+ALTER TABLE transactions DROP COLUMN source_line;
+ALTER TABLE transactions DROP COLUMN source_file;
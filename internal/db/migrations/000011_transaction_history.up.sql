@@ -0,0 +1,36 @@
+-- The CSV file and line imported transactions come from. Transactions created with trackit
+-- transaction create, or imported before these columns were added, have neither.
+ALTER TABLE transactions ADD COLUMN source_file TEXT;
+ALTER TABLE transactions ADD COLUMN source_line INTEGER;
+
+-- Changes to the fields of transactions, a row per field changed, whichever command made
+-- them. Accounts and categories are recorded by name, and amounts with two decimals.
+CREATE TABLE IF NOT EXISTS transaction_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transaction_id INTEGER NOT NULL,
+    changed_at TEXT NOT NULL DEFAULT (datetime('now', 'localtime')),
+    field TEXT NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
+
+CREATE TRIGGER transactions_history_update AFTER UPDATE ON transactions BEGIN
+    INSERT INTO transaction_history (transaction_id, field, old_value, new_value)
+    SELECT NEW.id, 'date', OLD."date", NEW."date" WHERE OLD."date" IS NOT NEW."date";
+    INSERT INTO transaction_history (transaction_id, field, old_value, new_value)
+    SELECT NEW.id, 'amount', printf('%.2f', OLD.amount), printf('%.2f', NEW.amount) WHERE OLD.amount IS NOT NEW.amount;
+    INSERT INTO transaction_history (transaction_id, field, old_value, new_value)
+    SELECT NEW.id, 'counter_party', OLD.counter_party, NEW.counter_party WHERE OLD.counter_party IS NOT NEW.counter_party;
+    INSERT INTO transaction_history (transaction_id, field, old_value, new_value)
+    SELECT NEW.id, 'description', OLD."description", NEW."description" WHERE OLD."description" IS NOT NEW."description";
+    INSERT INTO transaction_history (transaction_id, field, old_value, new_value)
+    SELECT NEW.id, 'account', (SELECT "name" FROM accounts WHERE id = OLD.account_id), (SELECT "name" FROM accounts WHERE id = NEW.account_id)
+    WHERE OLD.account_id IS NOT NEW.account_id;
+    INSERT INTO transaction_history (transaction_id, field, old_value, new_value)
+    SELECT NEW.id, 'category', (SELECT "name" FROM categories WHERE id = OLD.category_id), (SELECT "name" FROM categories WHERE id = NEW.category_id)
+    WHERE OLD.category_id IS NOT NEW.category_id;
+    INSERT INTO transaction_history (transaction_id, field, old_value, new_value)
+    SELECT NEW.id, 'ignored', CASE OLD.ignore_when_summing WHEN 1 THEN 'Yes' ELSE 'No' END, CASE NEW.ignore_when_summing WHEN 1 THEN 'Yes' ELSE 'No' END
+    WHERE OLD.ignore_when_summing IS NOT NEW.ignore_when_summing;
+END;
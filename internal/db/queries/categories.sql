@@ -13,3 +13,28 @@ DELETE FROM categories WHERE id=?;
 -- name: UpdateCategory :exec
 UPDATE categories SET name = sqlc.arg(newCategory) WHERE name = sqlc.arg(oldCategory);
 
+
+-- name: UpdateCategoryParent :exec
+UPDATE categories SET parent_id=? WHERE id=?;
+
+-- name: UpdateCategoryKind :exec
+UPDATE categories SET kind=? WHERE id=?;
+
+-- name: ReadCategoryUsage :one
+SELECT
+    (SELECT COUNT(*) FROM transactions WHERE transactions.category_id = CAST(sqlc.arg(id) AS INTEGER)) AS transaction_count,
+    (SELECT COUNT(*) FROM transaction_splits WHERE transaction_splits.category_id = CAST(sqlc.arg(id) AS INTEGER)) AS split_count,
+    (SELECT COUNT(*) FROM rules WHERE rules.category_id = CAST(sqlc.arg(id) AS INTEGER)) AS rule_count,
+    (SELECT COUNT(*) FROM categories WHERE categories.parent_id = CAST(sqlc.arg(id) AS INTEGER)) AS child_count;
+
+-- name: MoveCategoryTransactions :exec
+UPDATE transactions SET category_id=sqlc.arg(into_id) WHERE category_id=sqlc.arg(from_id);
+
+-- name: MoveCategorySplits :exec
+UPDATE transaction_splits SET category_id=sqlc.arg(into_id) WHERE category_id=sqlc.arg(from_id);
+
+-- name: MoveCategoryRules :exec
+UPDATE rules SET category_id=sqlc.arg(into_id) WHERE category_id=sqlc.arg(from_id);
+
+-- name: MoveCategoryChildren :exec
+UPDATE categories SET parent_id=sqlc.arg(into_id) WHERE parent_id=sqlc.arg(from_id);
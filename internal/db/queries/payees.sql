@@ -0,0 +1,36 @@
+-- name: CreatePayee :exec
+INSERT OR IGNORE INTO payees ("name") VALUES (?);
+
+-- name: ReadPayeeIdByName :one
+SELECT id FROM payees WHERE "name"=?;
+
+-- name: ReadAllPayees :many
+SELECT payees.id, payees.name, COUNT(transactions.id) AS transaction_count
+FROM payees
+LEFT JOIN transactions ON transactions.payee_id = payees.id
+GROUP BY payees.id
+ORDER BY payees.name;
+
+-- name: DeletePayee :exec
+DELETE FROM payees WHERE id=?;
+
+-- name: CreatePayeeAlias :exec
+INSERT INTO payee_aliases (payee_id, pattern) VALUES (?, ?);
+
+-- name: ReadAllPayeeAliases :many
+SELECT payee_aliases.*, payees.name AS payee_name
+FROM payee_aliases
+JOIN payees ON payees.id = payee_aliases.payee_id
+ORDER BY payee_aliases.id;
+
+-- name: MovePayeeAliases :exec
+UPDATE payee_aliases SET payee_id=sqlc.arg(into_id) WHERE payee_id=sqlc.arg(from_id);
+
+-- name: MovePayeeTransactions :exec
+UPDATE transactions SET payee_id=sqlc.arg(into_id) WHERE payee_id=sqlc.arg(from_id);
+
+-- name: ReadTransactionsWithoutPayee :many
+SELECT id, counter_party FROM transactions WHERE payee_id IS NULL;
+
+-- name: UpdateTransactionPayee :exec
+UPDATE transactions SET payee_id=? WHERE id=?;
@@ -0,0 +1,38 @@
+-- name: CreateRule :one
+INSERT INTO rules (priority, counter_party, "description", account_id, min_amount, max_amount, sign, from_date, to_date, category_id, ignore_when_summing, tags)
+    VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id;
+
+-- name: ReadAllRules :many
+SELECT
+    rules.*,
+    accounts.name AS account_name,
+    categories.name AS category_name
+FROM rules
+LEFT JOIN accounts ON accounts.id = rules.account_id
+LEFT JOIN categories ON categories.id = rules.category_id
+ORDER BY rules.priority, rules.id;
+
+-- name: ReadRuleById :one
+SELECT * FROM rules WHERE id=?;
+
+-- name: UpdateRule :exec
+UPDATE rules SET
+    priority=?,
+    counter_party=?,
+    "description"=?,
+    account_id=?,
+    min_amount=?,
+    max_amount=?,
+    sign=?,
+    from_date=?,
+    to_date=?,
+    category_id=?,
+    ignore_when_summing=?,
+    tags=?
+WHERE id=?;
+
+-- name: UpdateRulePriority :exec
+UPDATE rules SET priority=? WHERE id=?;
+
+-- name: DeleteRule :exec
+DELETE FROM rules WHERE id=?;
@@ -0,0 +1,12 @@
+-- name: CreateSavedQuery :exec
+INSERT INTO saved_queries ("name", command, args) VALUES (?, ?, ?)
+ON CONFLICT ("name") DO UPDATE SET command=excluded.command, args=excluded.args;
+
+-- name: ReadSavedQueryByName :one
+SELECT * FROM saved_queries WHERE "name"=?;
+
+-- name: ReadAllSavedQueries :many
+SELECT * FROM saved_queries ORDER BY "name";
+
+-- name: DeleteSavedQuery :execrows
+DELETE FROM saved_queries WHERE "name"=?;
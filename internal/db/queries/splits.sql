@@ -0,0 +1,12 @@
+-- name: CreateTransactionSplit :exec
+INSERT INTO transaction_splits (transaction_id, category_id, amount, memo) VALUES (?, ?, ?, ?);
+
+-- name: ReadTransactionSplits :many
+SELECT transaction_splits.*, categories.name AS category_name
+FROM transaction_splits
+LEFT JOIN categories ON categories.id = transaction_splits.category_id
+WHERE transaction_splits.transaction_id=?
+ORDER BY transaction_splits.id;
+
+-- name: DeleteTransactionSplits :exec
+DELETE FROM transaction_splits WHERE transaction_id=?;
@@ -0,0 +1,24 @@
+-- name: CreateTag :exec
+INSERT OR IGNORE INTO tags ("name") VALUES (?);
+
+-- name: ReadTagIdByName :one
+SELECT id FROM tags WHERE "name"=?;
+
+-- name: CreateTransactionTag :exec
+INSERT OR IGNORE INTO transaction_tags (transaction_id, tag_id) VALUES (?, ?);
+
+-- name: DeleteTransactionTag :exec
+DELETE FROM transaction_tags WHERE transaction_id=? AND tag_id=(SELECT id FROM tags WHERE "name"=?);
+
+-- name: ReadAllTransactionTags :many
+SELECT transaction_tags.transaction_id, tags.name AS tag_name
+FROM transaction_tags
+JOIN tags ON tags.id = transaction_tags.tag_id
+ORDER BY tags.name;
+
+-- name: ReadTransactionTags :many
+SELECT tags.name
+FROM transaction_tags
+JOIN tags ON tags.id = transaction_tags.tag_id
+WHERE transaction_tags.transaction_id=?
+ORDER BY tags.name;